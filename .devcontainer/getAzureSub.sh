@@ -17,6 +17,5 @@ echo -n "Please enter the Subscription ID you want to use: "
 # Prompt the user to enter a subscription ID
 read -r subscriptionId
 
-# Return the subscription ID that the user picked - put a blank line so that the cli picks only the subscription
-echo ""
-echo "$subscriptionId"
+# Return the subscription ID that the user picked.  everything else this script prints is only for the user
+echo "$subscriptionId" > "$DEVSECRETS_OUTPUT"
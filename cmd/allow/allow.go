@@ -0,0 +1,50 @@
+package allow
+
+import (
+	"devsecrets/config"
+	"devsecrets/globals"
+	"devsecrets/trust"
+	"os"
+)
+
+/*
+arrived via 'devsecrets allow'
+shows the user what changed in the manifest and its shell scripts since they were approved and records their approval,
+so that update runs the scripts again.  with --yes everything is approved without asking.
+*/
+func onAllow() {
+	config.LoadSecretFile()
+	trusted, err := trust.Load(config.GetTrustFileName(), config.GetConfigLockFileName())
+	if err != nil {
+		globals.EchoError("error reading " + config.GetTrustFileName() + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+	fileNames := append([]string{config.Value("input-file")}, config.ScriptFiles()...)
+
+	if config.FindSettingByName("yes").ValueB() {
+		for _, fileName := range fileNames {
+			change, err := trusted.Check(fileName)
+			if err != nil {
+				globals.EchoError("error reading ", fileName, " ", err.Error(), "\n")
+				os.Exit(2)
+			}
+			if change != nil {
+				trusted.Approve(*change)
+				globals.EchoInfo("approved ", change.FileName, "\n")
+			}
+		}
+		globals.PanicOnError(trusted.Save())
+		return
+	}
+
+	untrusted, err := trust.Review(trusted, fileNames, true)
+	if err != nil {
+		globals.EchoError("error checking if the scripts are trusted: ", err.Error(), "\n")
+		os.Exit(2)
+	}
+	if len(untrusted) != 0 {
+		globals.EchoWarning("not approved.  update won't run the scripts until they are\n")
+		os.Exit(1)
+	}
+	globals.EchoInfo("the manifest and its scripts are trusted\n")
+}
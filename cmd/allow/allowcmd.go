@@ -0,0 +1,25 @@
+package allow
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// AllowCmd represents the allow command
+var AllowCmd = &cobra.Command{
+	Use:   "allow",
+	Short: "approve the manifest and the shell scripts that update runs",
+	Long: `
+	devsecrets allow --input-file devsecrets.json [--yes]
+
+	update only runs shell scripts from a manifest and scripts that you approved.  when one of them is new or
+	changed (e.g. after pulling a branch), update shows what changed and asks.  allow does the same without
+	running anything, e.g. before a postCreateCommand that can't ask.  --yes approves without asking
+    `,
+	Run: func(cmd *cobra.Command, args []string) {
+		onAllow()
+	},
+}
+
+func init() {
+	AllowCmd.Flags().BoolP("yes", "y", false, "approve without showing the changes and asking")
+}
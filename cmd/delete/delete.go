@@ -1,14 +1,148 @@
 package delete
 
 import (
-
+	"devsecrets/config"
 	"devsecrets/globals"
+	"devsecrets/store"
+	"devsecrets/wrappers"
+	"fmt"
+	"os"
+	"strings"
 )
 
+const gitHubLocation = "GitHub user secrets"
+
+// one row in the summary table printed after a delete
+type deleteResult struct {
+	Name     string
+	Location string
+	Result   string
+	ok       bool
+}
+
 /*
-a quick but possibly unsafe delete -- delete the specified repo in GitHub and delete the ResourceGroup
+arrived via 'devsecrets delete --all | --name <name>'
+removes the secret(s) from the store of the project (its env file by default) and, when useGitHubUserSecrets is set, takes the
+current repo off of the GitHub user secrets.  the user is asked to confirm unless --yes is passed in.  shells that are already open keep the old values until they are restarted.
 */
 func onDelete() {
-	globals.EchoInfo(globals.ClearLineRight + "Deleting Portal Config\n")
-	
+	// the manifest says which project and store the secrets are in and if they are also GitHub user secrets.  without
+	// --input-file, it is the manifest of the project of the current directory
+	config.UseCurrentProject()
+	config.LoadSecretFile()
+	name := config.Value("name")
+	all := config.FindSettingByName("all").ValueB()
+	if name == "" && !all {
+		globals.EchoError("usage: devsecrets delete --all | --name <name>\n")
+		os.Exit(5)
+	}
+
+	unlock, err := wrappers.LockFile(config.GetLockFileName())
+	globals.PanicOnError(err)
+	defer unlock()
+
+	secretStore, err := store.FromConfig()
+	if err != nil {
+		globals.EchoError("Error loading config: ", err.Error(), "\n")
+		os.Exit(2)
+	}
+	location := secretStore.Location()
+	entries, err := secretStore.List()
+	if err != nil {
+		globals.EchoError("error reading " + location + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+
+	toDelete := []string{}
+	for _, e := range entries {
+		if all || e.Name == name {
+			toDelete = append(toDelete, e.Name)
+		}
+	}
+	if len(toDelete) == 0 {
+		globals.EchoWarning("Nothing to delete in ", location, "\n")
+		return
+	}
+
+	if !config.FindSettingByName("yes").ValueB() {
+		prompt := fmt.Sprint("Delete ", strings.Join(toDelete, ", "), " from ", location, "? [yN] ")
+		if !globals.EnterBoolean(prompt, false) {
+			globals.EchoInfo("Nothing deleted\n")
+			return
+		}
+	}
+
+	results := []deleteResult{}
+	failed := false
+	for _, n := range toDelete {
+		if err := secretStore.Delete(n); err != nil {
+			results = append(results, deleteResult{n, location, err.Error(), false})
+			failed = true
+		} else {
+			results = append(results, deleteResult{n, location, "deleted", true})
+		}
+	}
+
+	if config.LocalSecrets.Options.UseGitHubUserSecrets {
+		repo, repoErr := wrappers.GitHubRepository()
+		for _, n := range toDelete {
+			err := repoErr
+			if err == nil {
+				err = wrappers.DeleteGitHubUserSecret(n, repo)
+			}
+			if err != nil {
+				results = append(results, deleteResult{n, gitHubLocation, strings.TrimSpace(err.Error()), false})
+				failed = true
+			} else {
+				results = append(results, deleteResult{n, gitHubLocation, "removed from " + repo, true})
+			}
+		}
+	}
+
+	printResults(results)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func printResults(results []deleteResult) {
+	header := []string{"Number", "Environment Variable", "Location", "Result"}
+	toPrint := make([]globals.IConsolePrint, len(results))
+	for i, r := range results {
+		toPrint[i] = r
+	}
+	globals.PrintTable(header, toPrint)
+}
+
+// implement IConsolePrint for deleteResult
+func (r deleteResult) ColumnCount() int {
+	return 4
+}
+func (r deleteResult) Cell(row int, column int) string {
+	switch column {
+	case 0:
+		return fmt.Sprint(row)
+	case 1:
+		return r.Name
+	case 2:
+		return r.Location
+	case 3:
+		return r.Result
+	default:
+		panic("Bad column index passed in")
+	}
+}
+func (r deleteResult) CellColor(row int, col int) string {
+	if r.ok {
+		return globals.ColorGreen
+	}
+	return globals.ColorRed
+}
+func (r deleteResult) FillChar(row int, col int) string {
+	switch col {
+	case 0, 1, 2:
+		return "."
+	default:
+		return " "
+	}
 }
@@ -12,7 +12,7 @@ var DeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "delete one or all of the local secrets",
 	Long: ` 
-	devsecrets delete --all | --name <name> --verbose --input-file dev-secrets.json
+	devsecrets delete --all | --name <name> [--yes] --verbose --input-file dev-secrets.json
     `,
 	Run: func(cmd *cobra.Command, args []string) {
 		onDelete()
@@ -30,4 +30,5 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// repoCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	DeleteCmd.Flags().BoolP("yes", "y", false, "delete without asking for confirmation")
 }
@@ -0,0 +1,132 @@
+package env
+
+import (
+	"devsecrets/config"
+	"devsecrets/globals"
+	"devsecrets/projects"
+	"devsecrets/shells"
+	"devsecrets/store"
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*
+arrived via 'devsecrets env --shell <dialect> [--all]'
+writes the secrets in the store to stdout in the dialect of the shell (or file format) that will read them.
+everything else goes to stderr so that the output can be passed straight to eval.
+
+secrets without a value (e.g. skipped ones) aren't printed, so they never replace a value that is already set.
+the secrets are the ones of the project of --input-file, or, without it, of the project of the current directory.
+with --all, the secrets of every registered project are written.  when two projects have a secret with the same name,
+the project of the current directory wins, and otherwise the last one in alphabetical order.
+*/
+func onEnv() {
+	dialect := config.Value("shell")
+	if !shells.IsDialect(dialect) {
+		globals.EchoError("--shell must be one of: ", strings.Join(shells.Dialects, ", "), "\n")
+		os.Exit(5)
+	}
+
+	all := config.FindSettingByName("all").ValueB()
+	secretStores := []store.SecretStore{}
+	if all {
+		secretStores = registeredStores()
+	} else {
+		config.UseCurrentProject()
+		config.LoadSecretFile()
+		secretStore, err := store.FromConfig()
+		if err != nil {
+			globals.EchoError("Error loading config: ", err.Error(), "\n")
+			os.Exit(2)
+		}
+		secretStores = append(secretStores, secretStore)
+	}
+
+	vars := []shells.Variable{}
+	for _, secretStore := range secretStores {
+		entries, err := secretStore.List()
+		if err != nil {
+			globals.EchoError("error reading " + secretStore.Location() + " " + err.Error() + "\n")
+			if !all {
+				os.Exit(2)
+			}
+			continue
+		}
+		for _, e := range entries {
+			// a secret the user skipped is kept in the store without a value.  the hook loads the secrets before update
+			// runs, so printing it would wipe out a value that is already in the environment (e.g. from Codespaces)
+			if e.Value == "" {
+				continue
+			}
+			vars = setVariable(vars, shells.Variable{Name: e.Name, Value: e.Value})
+		}
+	}
+	out, err := shells.Format(dialect, vars)
+	if err != nil {
+		globals.EchoError(err.Error(), "\n")
+		os.Exit(5)
+	}
+	fmt.Print(out)
+
+	// lines that were added to the env file by hand are bash -- they can only be loaded by bash and zsh
+	for _, secretStore := range secretStores {
+		if u, ok := secretStore.(interface{ Unmanaged() (string, error) }); ok && (dialect == "bash" || dialect == "zsh") {
+			unmanaged, err := u.Unmanaged()
+			if err != nil {
+				globals.EchoError("error reading " + secretStore.Location() + " " + err.Error() + "\n")
+				if !all {
+					os.Exit(2)
+				}
+				continue
+			}
+			if unmanaged != "" {
+				fmt.Print(unmanaged, "\n")
+			}
+		}
+	}
+}
+
+/*
+returns the stores of every registered project, with the project of the current directory last so that its secrets
+win.  a project whose store can't be opened is reported and left out -- one broken project mustn't keep a new
+terminal from getting the secrets of the others
+*/
+func registeredStores() (secretStores []store.SecretStore) {
+	registry, err := projects.Load(config.GetProjectsFileName())
+	if err != nil {
+		globals.EchoError("error reading " + config.GetProjectsFileName() + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+	names := registry.Names()
+	if cwd, err := os.Getwd(); err == nil {
+		if current := registry.ForDir(cwd); current != "" {
+			for i, name := range names {
+				if name == current {
+					names = append(append(names[:i:i], names[i+1:]...), current)
+					break
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		secretStore, err := store.New(registry.Projects[name].Store, config.ProjectSecretFileName(name))
+		if err != nil {
+			globals.EchoError("project ", name, ": ", err.Error(), "\n")
+			continue
+		}
+		secretStores = append(secretStores, secretStore)
+	}
+	return
+}
+
+// sets the variable, replacing the value of one with the same name that an earlier project set
+func setVariable(vars []shells.Variable, v shells.Variable) []shells.Variable {
+	for i := range vars {
+		if vars[i].Name == v.Name {
+			vars[i] = v
+			return vars
+		}
+	}
+	return append(vars, v)
+}
@@ -0,0 +1,38 @@
+package env
+
+import (
+	"devsecrets/globals"
+	"devsecrets/shells"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// EnvCmd represents the env command
+var EnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "prints the secrets as statements for a shell to evaluate",
+	Long: ` prints the secrets in the store so that a shell can load them without the values being written to a file:
+
+    bash/zsh:    eval "$(devsecrets env --shell bash --input-file devsecrets.json)"
+    fish:        devsecrets env --shell fish --input-file devsecrets.json | source
+    PowerShell:  devsecrets env --shell pwsh --input-file devsecrets.json | Out-String | Invoke-Expression
+    nushell:     devsecrets env --shell json --input-file devsecrets.json | from json | load-env
+
+    --shell dotenv and --shell json print the secrets as a .env file or a json object
+
+    without --input-file, the secrets of the project of the current directory are printed.  with --all, the
+    secrets of every project that devsecrets update has run for are printed, e.g.
+
+    bash/zsh:    eval "$(devsecrets env --shell bash --all)"
+    `,
+	Annotations: map[string]string{globals.StdoutIsData: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		onEnv()
+	},
+}
+
+func init() {
+	EnvCmd.Flags().StringP("shell", "s", shells.DefaultDialect,
+		"the shell (or format) to print: "+strings.Join(shells.Dialects, "|"))
+}
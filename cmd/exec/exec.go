@@ -0,0 +1,80 @@
+package exec
+
+import (
+	"devsecrets/config"
+	"devsecrets/envfile"
+	"devsecrets/globals"
+	"devsecrets/store"
+	"os"
+	osexec "os/exec"
+	"strings"
+	"syscall"
+)
+
+/*
+arrived via 'devsecrets exec -- <command> [args...]'
+builds the environment for the command from the current environment plus the secrets in the store, then replaces
+this process with the command.  because it is the same process, the command gets the signals sent to devsecrets
+(e.g. ctrl-c) and whoever started devsecrets gets the exit code of the command.
+
+a secret that is already set in the environment keeps its value.  exec never prompts or runs a script, so a secret
+that update hasn't resolved yet has no value.  required secrets that don't have a value anywhere are reported on stderr
+(with a hint to run update) and the command is run without them.
+*/
+func onExec(args []string) {
+	config.LoadSecretFile()
+	secretStore, err := store.FromConfig()
+	if err != nil {
+		globals.EchoError("Error loading config: ", err.Error(), "\n")
+		os.Exit(2)
+	}
+	entries, err := secretStore.List()
+	if err != nil {
+		globals.EchoError("error reading " + secretStore.Location() + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+
+	env := os.Environ()
+	missing := []string{}
+	for _, s := range config.LocalSecrets.Secrets {
+		if val, found := os.LookupEnv(s.EnvironmentVariable); found && val != "" {
+			continue
+		}
+		i := envfile.Find(entries, s.EnvironmentVariable)
+		if i == -1 || entries[i].Value == "" {
+			if s.IsRequired() {
+				missing = append(missing, s.EnvironmentVariable)
+			}
+			continue
+		}
+		env = setEnv(env, s.EnvironmentVariable, entries[i].Value)
+	}
+	if len(missing) > 0 {
+		globals.EchoWarning("these secrets do not have a value: ", strings.Join(missing, ", "),
+			".  run 'devsecrets update' to get them\n")
+	}
+
+	path, err := osexec.LookPath(args[0])
+	if err != nil {
+		globals.EchoError(err.Error(), "\n")
+		os.Exit(127) // what a shell returns for "command not found"
+	}
+	err = syscall.Exec(path, args, env)
+	// only get here if the exec failed
+	globals.EchoError("unable to run ", args[0], ": ", err.Error(), "\n")
+	os.Exit(126) // what a shell returns for "found but can't execute"
+}
+
+/*
+sets the variable in the environment.  a variable that is exported but empty is already in it, and the command would
+get both -- getenv() in C returns the first one, which is the empty one
+*/
+func setEnv(env []string, name string, value string) []string {
+	kept := []string{}
+	for _, e := range env {
+		if !strings.HasPrefix(e, name+"=") {
+			kept = append(kept, e)
+		}
+	}
+	return append(kept, name+"="+value)
+}
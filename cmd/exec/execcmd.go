@@ -0,0 +1,31 @@
+package exec
+
+import (
+	"devsecrets/globals"
+
+	"github.com/spf13/cobra"
+)
+
+// ExecCmd represents the exec command
+var ExecCmd = &cobra.Command{
+	Use:   "exec -- <command> [args...]",
+	Short: "runs a command with the secrets in its environment",
+	Long: ` runs a command with the secrets added to its environment.  nothing is written to disk and the secrets
+    are only in the environment of that command:
+
+    devsecrets exec --input-file devsecrets.json -- go test ./...
+
+    exec only reads the values that are already in the store -- it doesn't prompt or run any scripts.  run
+    'devsecrets update' first to get the values of new secrets.
+    `,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{globals.StdoutIsData: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		onExec(args)
+	},
+}
+
+func init() {
+	// everything after the command belongs to the command, not to devsecrets
+	ExecCmd.Flags().SetInterspersed(false)
+}
@@ -4,9 +4,13 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"devsecrets/cmd/update"
+	"devsecrets/cmd/allow"
 	"devsecrets/cmd/delete"
+	"devsecrets/cmd/env"
+	"devsecrets/cmd/exec"
 	"devsecrets/cmd/setup"
+	"devsecrets/cmd/uninstall"
+	"devsecrets/cmd/update"
 	"devsecrets/cmd/verify"
 	"devsecrets/config"
 	"devsecrets/globals"
@@ -33,6 +37,10 @@ var rootCmd = &cobra.Command{
 	devsecrets setup
 	devsecrets update --all | --name <name> --input-file devsecrets.json --verbose
 	devscecreats delete --all | --name <name>
+	devsecrets env [--all]
+	devsecrets exec -- <command> [args...]
+	devsecrets allow
+	devsecrets uninstall [--purge]
 
 `, PersistentPreRunE: OnPreRun,
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
@@ -40,8 +48,14 @@ var rootCmd = &cobra.Command{
 		globals.EchoInfo("")
 	},
 }
+
 func OnPreRun(cmd *cobra.Command, args []string) error {
-	
+	if _, found := cmd.Annotations[globals.StdoutIsData]; found {
+		globals.EchoToStderr = true
+	}
+	if cmd == update.UpdateCmd && update.IsNonInteractive(cmd) {
+		globals.EchoToStderr = true
+	}
 	// You can bind cobra and viper in a few locations, but PersistencePreRunE on the root command works well
 	err := initConfig(cmd)
 	if err != nil {
@@ -50,6 +64,7 @@ func OnPreRun(cmd *cobra.Command, args []string) error {
 	}
 	return err
 }
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -70,6 +85,10 @@ func init() {
 	rootCmd.AddCommand(delete.DeleteCmd)
 	rootCmd.AddCommand(verify.VerifyCmd)
 	rootCmd.AddCommand(setup.SetupCmd)
+	rootCmd.AddCommand(env.EnvCmd)
+	rootCmd.AddCommand(exec.ExecCmd)
+	rootCmd.AddCommand(allow.AllowCmd)
+	rootCmd.AddCommand(uninstall.UninstallCmd)
 
 	// global
 
@@ -88,7 +107,7 @@ The way that config is being used is:
 - there are settings that are in a config file (e.g. "local-input.yaml")
 - this function uses Viper to look in various places for anything named "coral-config-settings.yaml"
 - if a file is passed in via --input-file, it looks there instead
-- if an environment variable is set matching the parameter's name, it picks up that value
+- if an environment variable is set matching the parameter's name (prefixed with DEVSECRETS_), it picks up that value
 - then it takes the data that it found and calls bindFlags, which copies the data in the Cobra structures
 - finally, it passes the data to the config system which initializes itself using the Cobra structures
 */
@@ -110,7 +129,11 @@ func initConfig(cmd *cobra.Command) error {
 	viper.AddConfigPath(".")
 	viper.AddConfigPath("./")
 	viper.AddConfigPath("$HOME")
-	viper.AutomaticEnv() // read in environment variables that match
+	// read in environment variables that match, e.g. DEVSECRETS_INPUT_FILE for --input-file.  without the prefix,
+	// common variables like $SHELL and $NAME would be taken as flags
+	viper.SetEnvPrefix("DEVSECRETS")
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		globals.EchoWarning("Using Secrets File:", viper.ConfigFileUsed(), "\n")
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"devsecrets/config"
+	"devsecrets/envfile"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+runs devsecrets in a child process of the test binary, because commands call os.Exit().  the child runs Execute()
+with the args instead of the tests
+*/
+func runDevsecrets(t *testing.T, home string, env []string, args ...string) (stdout string, exitCode int) {
+	return runDevsecretsWithStdin(t, home, env, "", args...)
+}
+
+// like runDevsecrets(), with the answers to the prompts on stdin
+func runDevsecretsWithStdin(t *testing.T, home string, env []string, stdin string, args ...string) (stdout string, exitCode int) {
+	if os.Getenv("DEVSECRETS_TEST_ARGS") != "" {
+		t.Skip("already in the child")
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMain$")
+	cmd.Dir = home
+	cmd.Env = append(os.Environ(), "HOME="+home, "DEVSECRETS_TEST_ARGS="+strings.Join(args, "\x1f"))
+	cmd.Env = append(cmd.Env, env...)
+	var out bytes.Buffer
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &out
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("running devsecrets %v: %v", args, err)
+	}
+	return out.String(), exitCode
+}
+
+func TestMain(m *testing.M) {
+	if args := os.Getenv("DEVSECRETS_TEST_ARGS"); args != "" {
+		os.Args = append([]string{"devsecrets"}, strings.Split(args, "\x1f")...)
+		Execute()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// a caller parses what update --non-interactive prints -- nothing else, not even a color code, can be on stdout
+func TestNonInteractiveStdoutIsJSON(t *testing.T) {
+	home := t.TempDir()
+	manifest := filepath.Join(home, "devsecrets.json")
+	os.WriteFile(manifest, []byte(`{"secrets": [{"environmentVariable": "DEVSECRETS_TEST_FOO", "description": "foo", "shellscript": ""}]}`), 0644)
+
+	tests := []struct {
+		name string
+		env  []string
+		args []string
+	}{
+		{"flag", nil, []string{"update", "--all", "--non-interactive", "--input-file", manifest}},
+		{"environment", []string{"DEVSECRETS_NONINTERACTIVE=true"}, []string{"update", "--all", "--input-file", manifest}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, exitCode := runDevsecrets(t, home, tt.env, tt.args...)
+			if want := `{"missing":["DEVSECRETS_TEST_FOO"]}` + "\n"; stdout != want || exitCode != 3 {
+				t.Errorf("stdout = %q, exit code %d, want %q, exit code 3", stdout, exitCode, want)
+			}
+		})
+	}
+}
+
+// a skipped secret has no value in the store.  printing it would replace the value the shell already has with an empty one
+func TestEnvLeavesOutSkippedSecrets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	manifest := filepath.Join(home, "devsecrets.json")
+	os.WriteFile(manifest, []byte(`{"secrets": [
+		{"environmentVariable": "FOO", "description": "foo", "shellscript": ""},
+		{"environmentVariable": "BAR", "description": "bar", "shellscript": "", "required": false}]}`), 0644)
+	envfile.Write(config.ProjectSecretFileName(config.DefaultProjectName(manifest)), []envfile.Entry{
+		{Name: "FOO", Value: "x", Source: config.SourcePrompt},
+		{Name: "BAR", Source: config.SourceSkipped},
+	})
+
+	stdout, exitCode := runDevsecrets(t, home, []string{"BAR=fromcodespace"}, "env", "--shell", "bash", "--input-file", manifest)
+	if want := "export FOO='x'\n"; stdout != want || exitCode != 0 {
+		t.Errorf("stdout = %q, exit code %d, want %q", stdout, exitCode, want)
+	}
+}
+
+// a secret that is exported but empty gets the value from the store, and the command only gets one copy of it
+func TestExecReplacesEmptyVariables(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	manifest := filepath.Join(home, "devsecrets.json")
+	os.WriteFile(manifest, []byte(`{"secrets": [{"environmentVariable": "FOO_PAT", "description": "foo", "shellscript": ""}]}`), 0644)
+	envfile.Write(config.ProjectSecretFileName(config.DefaultProjectName(manifest)), []envfile.Entry{
+		{Name: "FOO_PAT", Value: "bar", Source: config.SourcePrompt},
+	})
+
+	stdout, exitCode := runDevsecrets(t, home, []string{"FOO_PAT="}, "exec", "--input-file", manifest, "--", "env")
+	got := []string{}
+	for _, line := range strings.Split(stdout, "\n") {
+		if strings.HasPrefix(line, "FOO_PAT=") {
+			got = append(got, line)
+		}
+	}
+	if len(got) != 1 || got[0] != "FOO_PAT=bar" || exitCode != 0 {
+		t.Errorf("the command got %q, exit code %d, want just FOO_PAT=bar", got, exitCode)
+	}
+}
+
+// a manifest without scripts has nothing to approve -- asking would take the answer meant for the first prompt
+func TestNoTrustPromptWithoutScripts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	manifest := filepath.Join(home, "devsecrets.json")
+	os.WriteFile(manifest, []byte(`{"secrets": [{"environmentVariable": "DEVSECRETS_TEST_FOO", "description": "foo", "shellscript": ""}]}`), 0644)
+
+	stdout, exitCode := runDevsecretsWithStdin(t, home, nil, "foo-value\n",
+		"update", "--all", "--interactive", "always", "--input-file", manifest)
+	if strings.Contains(stdout, "trust") || strings.Contains(stdout, "not running scripts") || exitCode != 0 {
+		t.Errorf("update asked about trust: %q, exit code %d", stdout, exitCode)
+	}
+	entries, _ := envfile.Read(config.ProjectSecretFileName(config.DefaultProjectName(manifest)))
+	if i := envfile.Find(entries, "DEVSECRETS_TEST_FOO"); i == -1 || entries[i].Value != "foo-value" {
+		t.Errorf("the store has %+v, want DEVSECRETS_TEST_FOO=foo-value", entries)
+	}
+}
@@ -10,10 +10,13 @@ package setup
 
 import (
 	"devsecrets/config"
+	"devsecrets/globals"
+	"devsecrets/projects"
+	"devsecrets/shells"
 	"devsecrets/wrappers"
-	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -27,54 +30,138 @@ var SetupCmd = &cobra.Command{
     Examples: 
 
     devsecrets setup --input-file ./devsecrets.json --verbose
-    
+    devsecrets setup --input-file ./devsecrets.json --dry-run
+    devsecrets setup --input-file ./devsecrets.json --shell bash,fish
+    sudo devsecrets setup --input-file /workspaces/app/devsecrets.json --shell profile.d
+
+    setup writes its lines between "# >>> devsecrets >>>" and "# <<< devsecrets <<<" and only ever
+    replaces that block.  the startup file is backed up first.  --dry-run shows the changes instead
+
+    the block runs update for every project setup or update has run for, and loads the secrets of all of them
+
+    without --shell, setup installs into the startup file of every shell on the PATH:
+
+    bash:        ~/.bashrc
+    zsh:         ~/.zshrc
+    fish:        ~/.config/fish/conf.d/devsecrets.fish
+    pwsh:        ~/.config/powershell/Microsoft.PowerShell_profile.ps1 ($PROFILE)
+    nu:          ~/.config/nushell/env.nu
+    profile.d:   /etc/profile.d/devsecrets.sh, for every user (only with --shell)
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		OnSetup()
 	},
 }
 
-var secretEnvFile = ".devsecrets.env"
-
 /*
 arrived via 'devsecrets setup <flags>'
 this should be called when the container is created.  its job is to
-*. create $HOME/.devscrets.env
-*. update the .bashrc to call 'devsecrets update --input-file <file> --all --verbose'
-*. update the .bashrc to call 'source  $HOME/.devscrets.env'
+*. register the project of the manifest (see config.RegisterProject())
+*. update the startup file of each shell to call 'devsecrets update --input-file <file> --all --verbose' for each
+registered project
+*. update the startup file of each shell to load the secrets of all of them with 'devsecrets env --shell <shell> --all'
+the shells are the ones in --shell, or the ones that are installed
 */
 func OnSetup() error {
+	config.LoadSecretFile()
 
-	// touch the .env file
-	secretEnvFilePath := config.GetSecretFileName()
-
-	// update the .bashrc
-	cwd, _ := os.Getwd()
-	jsonSecretsInputFile := filepath.Join(cwd, config.Value("input-file"))
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		panic(err)
 	}
 
 	exeFileSpec, _ := os.Executable()
+	dryRun := config.FindSettingByName("dry-run").ValueB()
+	if !dryRun {
+		globals.PanicOnError(config.RegisterProject())
+	}
+	manifests := registeredManifests()
 
-	secretUpdateCmd := fmt.Sprint(exeFileSpec, " update --verbose --all --input-file ", jsonSecretsInputFile)
-	
-	// we need to source the .env file before *and* after running update so that update can see the values of
-	// the environment variables
-	toWrite := fmt.Sprint("source ", secretEnvFilePath, "\n",
-		secretUpdateCmd, "\n",
-		"source ", secretEnvFilePath, "\n")
+	startupFiles := shells.Installed(shells.StartupFiles(homeDir))
+	if names := config.Value("shell"); names != "" {
+		startupFiles, err = shells.Select(shells.StartupFiles(homeDir), strings.FieldsFunc(names, isSeparator))
+		if err != nil {
+			globals.EchoError(err.Error(), "\n")
+			os.Exit(5)
+		}
+	}
+	if len(startupFiles) == 0 {
+		globals.EchoWarning("none of the shells setup supports is on the PATH.  use --shell to pick them\n")
+	}
 
-	updateShellStartupFile(filepath.Join(homeDir, ".bashrc"), "devsecrets", toWrite)
-	updateShellStartupFile(filepath.Join(homeDir, ".zshrc"), "devsecrets", toWrite)
+	for _, rc := range startupFiles {
+		toWrite, err := shells.Hook(rc.Dialect, exeFileSpec, manifests)
+		globals.PanicOnError(err)
+		updateShellStartupFile(rc.FileName, toWrite, dryRun)
+	}
 	return nil
 }
+
+/*
+returns the manifests of the registered projects, in the order of their names, and the one setup is running for.  a
+manifest that was deleted (e.g. with its repo) is left out
+*/
+func registeredManifests() (manifests []string) {
+	registry, err := projects.Load(config.GetProjectsFileName())
+	if err != nil {
+		globals.EchoError("error reading " + config.GetProjectsFileName() + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+	seen := map[string]bool{}
+	for _, name := range registry.Names() {
+		manifest := registry.Projects[name].Manifest
+		if _, err := os.Stat(manifest); err == nil && !seen[manifest] {
+			manifests = append(manifests, manifest)
+			seen[manifest] = true
+		}
+	}
+	// with --dry-run the project isn't registered
+	if manifest, err := filepath.Abs(config.Value("input-file")); err == nil && !seen[manifest] {
+		manifests = append(manifests, manifest)
+	}
+	return
+}
+
 /*
-this deletes all lines that have the word "devsecrets" in it. i'm worried that somebody or some tool might add
-the executable to the path and then we blast the path.  need to fix.
+writes the hook into the devsecrets block of the startup file (see shells.InstallBlock()) and leaves every other line
+alone.  the old file is kept next to it with a timestamp in its name.  with dryRun, the change is printed instead
 */
-func updateShellStartupFile(startupFile string, deleteString string, toWrite string) {
-	wrappers.RemoveLinesContaintainingString(startupFile, deleteString)
-	wrappers.AppendFile(startupFile, toWrite)
+func updateShellStartupFile(startupFile string, hook string, dryRun bool) {
+	startupFile, old, err := shells.ReadStartupFile(startupFile)
+	if err != nil {
+		globals.EchoError("error reading ", startupFile, " ", err.Error(), "\n")
+		os.Exit(2)
+	}
+	updated := shells.InstallBlock(old, hook)
+	if updated == old {
+		globals.EchoInfo(startupFile, " is up to date\n")
+		return
+	}
+	if dryRun {
+		globals.EchoInfo("setup would change ", startupFile, ":\n", wrappers.Diff(old, updated), "\n")
+		return
+	}
+
+	backup, err := shells.WriteStartupFile(startupFile, updated)
+	globals.PanicOnError(err)
+	if backup != "" {
+		globals.EchoInfo("saved ", startupFile, " as ", backup, "\n")
+	}
+	globals.EchoInfo("updated ", startupFile, "\n")
+}
+
+func init() {
+	SetupCmd.Flags().Bool("dry-run", false, "show the changes to the startup files instead of making them")
+	SetupCmd.Flags().StringP("shell", "s", "", "a comma separated list of the shells to set up: "+strings.Join(shellNames(), "|"))
+}
+
+func shellNames() (names []string) {
+	for _, f := range shells.StartupFiles("~") {
+		names = append(names, f.Shell)
+	}
+	return
+}
+
+func isSeparator(r rune) bool {
+	return r == ',' || r == ' '
 }
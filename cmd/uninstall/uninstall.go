@@ -0,0 +1,262 @@
+package uninstall
+
+import (
+	"devsecrets/config"
+	"devsecrets/globals"
+	"devsecrets/projects"
+	"devsecrets/shells"
+	"devsecrets/store"
+	"devsecrets/wrappers"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// one row in the summary table printed after an uninstall.  only files that uninstall touched get a row
+type uninstallResult struct {
+	FileName string
+	Result   string
+	ok       bool
+}
+
+/*
+arrived via 'devsecrets uninstall [--purge]'
+reverses setup: the devsecrets block is removed from the startup file of every shell, and the trust and state files
+are deleted.  with --purge the files of every store of every project (the env file, the encrypted file and its key)
+and the list of projects are deleted too, after the user confirms unless --yes is passed in.  shells that are already open keep the secrets until they are
+restarted.
+*/
+func onUninstall() {
+	homeDir, err := os.UserHomeDir()
+	globals.PanicOnError(err)
+	purge := config.FindSettingByName("purge").ValueB()
+	// config.GetConfigDir() creates the directory, so whether there was one to remove has to be known up front
+	configDir := filepath.Join(homeDir, ".config", "devsecrets")
+	hadConfigDir := exists(configDir)
+
+	// the env file of every project, and the one versions before projects kept the secrets of all of them in
+	projectDirs, _ := filepath.Glob(filepath.Join(configDir, "projects", "*"))
+	secretFileNames := []string{config.GetLegacySecretFileName()}
+	for _, dir := range projectDirs {
+		secretFileNames = append(secretFileNames, config.ProjectSecretFileName(filepath.Base(dir)))
+	}
+	storeFiles := existingStoreFiles(secretFileNames...)
+	if purge && len(storeFiles) != 0 && !config.FindSettingByName("yes").ValueB() {
+		prompt := fmt.Sprint("Delete ", strings.Join(storeFiles, ", "), "? The secrets in them can't be recovered [yN] ")
+		if !globals.EnterBoolean(prompt, false) {
+			globals.EchoInfo("Nothing uninstalled\n")
+			return
+		}
+	}
+
+	// the manifests are only known from projects.json, which --purge deletes
+	var gitHubSecrets []string
+	if purge {
+		gitHubSecrets = gitHubUserSecrets()
+	}
+
+	results := []uninstallResult{}
+	for _, rc := range shells.StartupFiles(homeDir) {
+		results = append(results, removeBlock(rc)...)
+	}
+	// state.json is where versions before projects kept the state of every project
+	toRemove := []string{config.GetTrustFileName(), config.GetConfigLockFileName(), filepath.Join(configDir, "state.json")}
+	for _, dir := range projectDirs {
+		toRemove = append(toRemove, config.ProjectStateFileName(filepath.Base(dir)))
+	}
+	for _, fileName := range toRemove {
+		results = append(results, removeFile(fileName)...)
+	}
+	if purge {
+		for _, secretFileName := range secretFileNames {
+			results = append(results, purgeStore(secretFileName)...)
+		}
+		results = append(results, removeFile(config.GetProjectsFileName())...)
+	}
+	// the directories are only removed if nothing else is left in them
+	for _, dir := range append(projectDirs, filepath.Join(configDir, "projects"), configDir) {
+		if os.Remove(dir) == nil && (dir != configDir || hadConfigDir) {
+			results = append(results, uninstallResult{dir, "removed", true})
+		}
+	}
+
+	if len(results) == 0 {
+		globals.EchoInfo("Nothing to uninstall\n")
+	} else {
+		printResults(results)
+	}
+	if !purge && len(storeFiles) != 0 {
+		globals.EchoInfo("The secrets are still in ", strings.Join(storeFiles, ", "), ".  Use --purge to delete them\n")
+	}
+	for _, left := range gitHubSecrets {
+		globals.EchoWarning(left, "\n")
+	}
+	for _, r := range results {
+		if !r.ok {
+			os.Exit(1)
+		}
+	}
+}
+
+/*
+--purge only deletes files, so the GitHub user secrets that the projects with useGitHubUserSecrets saved are left
+behind -- the store and the manifest can't tell which of them are still used by other repos.  returns a line for each
+of those projects that names the secrets and says how to remove them
+*/
+func gitHubUserSecrets() (left []string) {
+	registry, err := projects.Load(config.GetProjectsFileName())
+	if err != nil {
+		return []string{"unable to read " + config.GetProjectsFileName() + " to find GitHub user secrets: " + err.Error()}
+	}
+	for _, name := range registry.Names() {
+		manifest := registry.Projects[name].Manifest
+		bytes, err := os.ReadFile(manifest)
+		var secrets config.DevSecrets
+		if err == nil {
+			err = json.Unmarshal(bytes, &secrets)
+		}
+		if err != nil {
+			left = append(left, fmt.Sprint("unable to read ", manifest, " to find its GitHub user secrets: ", err.Error()))
+			continue
+		}
+		if !secrets.Options.UseGitHubUserSecrets || len(secrets.Secrets) == 0 {
+			continue
+		}
+		names := []string{}
+		for _, s := range secrets.Secrets {
+			names = append(names, s.EnvironmentVariable)
+		}
+		left = append(left, fmt.Sprint("The GitHub user secrets of ", manifest, " (", strings.Join(names, ", "),
+			") were left behind.  Remove them in https://github.com/settings/codespaces or with 'gh secret delete <name> --user'"))
+	}
+	return
+}
+
+/*
+removes the devsecrets block from a startup file.  a file that setup created just for devsecrets is deleted when there
+is nothing else left in it.  no result if there isn't a block in the file
+*/
+func removeBlock(rc shells.StartupFile) []uninstallResult {
+	startupFile, contents, err := shells.ReadStartupFile(rc.FileName)
+	if err != nil {
+		return []uninstallResult{{startupFile, err.Error(), false}}
+	}
+	updated, removed := shells.RemoveBlock(contents)
+	if !removed {
+		return nil
+	}
+	if rc.Owned && strings.TrimSpace(updated) == "" {
+		return removeFile(startupFile)
+	}
+	backup, err := shells.WriteStartupFile(startupFile, updated)
+	if err != nil {
+		return []uninstallResult{{startupFile, err.Error(), false}}
+	}
+	return []uninstallResult{{startupFile, "removed the devsecrets block, saved the old file as " + backup, true}}
+}
+
+// returns the files of every store for the env files that exist.  the key file is shared, so it is only returned once
+func existingStoreFiles(secretFileNames ...string) (files []string) {
+	seen := map[string]bool{}
+	for _, secretFileName := range secretFileNames {
+		for _, name := range store.Names() {
+			secretStore, err := store.New(name, secretFileName)
+			globals.PanicOnError(err)
+			fileBacked, ok := secretStore.(store.FileBacked)
+			if !ok {
+				continue
+			}
+			for _, fileName := range fileBacked.Files() {
+				if exists(fileName) && !seen[fileName] {
+					files = append(files, fileName)
+					seen[fileName] = true
+				}
+			}
+		}
+	}
+	return
+}
+
+/*
+deletes the files of every store for the env file while holding its lock, so that an update that is running can't
+write them back, and then the lock file.  a lock file uninstall only created to hold the lock isn't reported
+*/
+func purgeStore(secretFileName string) (results []uninstallResult) {
+	lockFileName := secretFileName + ".lock"
+	files := existingStoreFiles(secretFileName)
+	if len(files) == 0 {
+		return removeFile(lockFileName)
+	}
+	hadLockFile := exists(lockFileName)
+	unlock, err := wrappers.LockFile(lockFileName)
+	globals.PanicOnError(err)
+	for _, fileName := range files {
+		results = append(results, removeFile(fileName)...)
+	}
+	unlock()
+	if removed := removeFile(lockFileName); hadLockFile {
+		results = append(results, removed...)
+	}
+	return
+}
+
+// deletes a file.  no result if the file doesn't exist
+func removeFile(fileName string) []uninstallResult {
+	err := os.Remove(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return []uninstallResult{{fileName, err.Error(), false}}
+	}
+	return []uninstallResult{{fileName, "removed", true}}
+}
+
+func exists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
+}
+
+func printResults(results []uninstallResult) {
+	header := []string{"Number", "File", "Result"}
+	toPrint := make([]globals.IConsolePrint, len(results))
+	for i, r := range results {
+		toPrint[i] = r
+	}
+	globals.PrintTable(header, toPrint)
+}
+
+// implement IConsolePrint for uninstallResult
+func (r uninstallResult) ColumnCount() int {
+	return 3
+}
+func (r uninstallResult) Cell(row int, column int) string {
+	switch column {
+	case 0:
+		return fmt.Sprint(row)
+	case 1:
+		return r.FileName
+	case 2:
+		return r.Result
+	default:
+		panic("Bad column index passed in")
+	}
+}
+func (r uninstallResult) CellColor(row int, col int) string {
+	if r.ok {
+		return globals.ColorGreen
+	}
+	return globals.ColorRed
+}
+func (r uninstallResult) FillChar(row int, col int) string {
+	switch col {
+	case 0, 1:
+		return "."
+	default:
+		return " "
+	}
+}
@@ -0,0 +1,26 @@
+package uninstall
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// UninstallCmd represents the uninstall command
+var UninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "undo setup: remove devsecrets from the shell startup files",
+	Long: `
+	devsecrets uninstall [--purge] [--yes]
+
+	removes the block that setup wrote from every shell startup file (each file is backed up first) and the
+	files that devsecrets keeps in $HOME/.config/devsecrets.  the secrets stay where they are unless --purge is
+	passed in, which also deletes them from every store.  --yes purges without asking for confirmation
+    `,
+	Run: func(cmd *cobra.Command, args []string) {
+		onUninstall()
+	},
+}
+
+func init() {
+	UninstallCmd.Flags().Bool("purge", false, "also delete the secrets and the encryption key")
+	UninstallCmd.Flags().BoolP("yes", "y", false, "purge without asking for confirmation")
+}
@@ -11,48 +11,625 @@ package update
 
 import (
 	"devsecrets/config"
+	"devsecrets/envfile"
 	"devsecrets/globals"
+	"devsecrets/state"
+	"devsecrets/store"
+	"devsecrets/trust"
 	"devsecrets/wrappers"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 /*
 called by .bashrc (or .zshrc) *before* the secrets.env file is loaded.
-we look at the json passed in and construct the string that should be
-in the file and replace the file with the new string.
+we look at the json passed in, resolve every secret and save the values
+in the store of the project selected by options.store (the project's env file by default).
 
-if the environment variable is set, we use that value.  if not, we ask
-the use what value to use
+if the environment variable is set, we use that value.  if not, we use
+the value in the store, and if there isn't one we ask the use what value
+to use.  secrets that are in the store but no longer in the json are
+removed -- nothing else in the store is touched.
+
+with --name, only that secret is resolved -- and it is always re-resolved, even if it already has a value. every
+other secret in the store is left as it is.
+
+update runs every time a terminal opens, so it must not hang a terminal that nobody can type into (e.g. a VS Code
+task).  with --interactive=auto it only prompts if stdin is a terminal, and otherwise prints one line with the secrets
+it couldn't get.  secrets the user decided to skip are recorded in the state file and not asked for again until the
+decision expires.
+
+with --non-interactive (or DEVSECRETS_NONINTERACTIVE=true), e.g. in a postCreateCommand, a prebuild or CI, update
+never prompts.  the values come from the environment, --from-file, the store and the shell scripts, and if a required
+secret still doesn't have a value, update prints {"missing": [...]} to stdout and exits with
+globals.ExitMissingSecrets.  everything else it prints goes to stderr so that stdout can be parsed.
 */
 func OnUpdate() {
 	config.LoadSecretFile()
-	var toWrite string
-	for _, s := range config.LocalSecrets.Secrets {
-		// is the value set?
-		val := os.Getenv(s.EnvironmentVariable)
-		if val == "" {
-			if s.ShellScript == "" {
-				prompt := fmt.Sprint("Enter value for ", s.EnvironmentVariable, ": ")
-				val = globals.EnterString(prompt)
-			} else {
-				val, _ = wrappers.ExecBash(s.ShellScript)
-			}
 
+	// OnPreRun already sent the Echo* functions to stderr -- see IsNonInteractive()
+	nonInteractive = config.FindSettingByName("non-interactive").ValueB() || envIsTrue(NonInteractiveEnvVar)
+	switch interactive := config.Value("interactive"); {
+	case nonInteractive && interactive == "always":
+		globals.EchoError("--interactive=always can't be used with --non-interactive\n")
+		os.Exit(5)
+	case nonInteractive || interactive == "never":
+		canPrompt = false
+	case interactive == "auto":
+		canPrompt = globals.StdinIsTerminal()
+	case interactive == "always":
+		canPrompt = true
+	default:
+		globals.EchoError("--interactive must be auto, always or never, not \"", interactive, "\"\n")
+		os.Exit(5)
+	}
+	if fromFile := config.Value("from-file"); fromFile != "" {
+		var err error
+		if fromFileValues, err = envfile.ReadDotenv(fromFile); err != nil {
+			globals.EchoError("error reading --from-file ", err.Error(), "\n")
+			os.Exit(2)
 		}
+	}
+
+	updateSecrets()
 
-		toWrite += fmt.Sprint("# ", s.Description, "\n",
-			s.EnvironmentVariable, "=", val, "\n",
-			"export ", s.EnvironmentVariable, "\n", "\n")
+	if nonInteractive && len(missing) > 0 {
+		sort.Strings(missing) // the secrets are resolved at the same time, so they go missing in any order
+		bytes, err := json.Marshal(map[string][]string{"missing": missing})
+		globals.PanicOnError(err)
+		fmt.Println(string(bytes))
+		globals.EchoError(fmt.Sprint(len(missing), " required secret(s) do not have a value: ", strings.Join(missing, ", "), "\n"))
+		os.Exit(globals.ExitMissingSecrets)
 	}
+}
+
+// set to anything but "", "0" or "false" to run update with --non-interactive
+const NonInteractiveEnvVar = "DEVSECRETS_NONINTERACTIVE"
+
+func envIsTrue(name string) bool {
+	val := strings.ToLower(os.Getenv(name))
+	return val != "" && val != "0" && val != "false"
+}
 
-	// globals.EchoInfo(toWrite)
+// resolves the secrets and writes them to the store while holding the lock
+func updateSecrets() {
+	// terminals that open at the same time each run update -- only one of them gets to change the store at a time
+	unlock, err := wrappers.LockFile(config.GetLockFileName())
+	globals.PanicOnError(err)
+	defer unlock()
 
-	envFile := config.GetSecretFileName()
-	file, err := os.Create(envFile)
+	secretStore, err := store.FromConfig()
+	if err != nil {
+		globals.EchoError("Error loading config: ", err.Error(), "\n")
+		os.Exit(2)
+	}
+	existing, err := secretStore.List()
 	globals.PanicOnError(err)
-	defer file.Close()
+	globals.PanicOnError(config.RegisterProject())
+	existing = importLegacySecrets(secretStore, existing)
+	decisions, err = state.Load(config.GetStateFileName())
+	if err != nil {
+		globals.EchoError("error reading " + config.GetStateFileName() + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+	defer warnNotPrompted()
+	defer func() {
+		globals.PanicOnError(decisions.Save())
+	}()
+	reviewTrust()
+
+	name := config.Value("name")
+	if name != "" && !config.FindSettingByName("all").ValueB() {
+		secret := config.FindSecret(name)
+		if secret == nil {
+			globals.EchoError(name, " is not in ", config.Value("input-file"), "\n")
+			os.Exit(2)
+		}
+		mu.Lock()
+		setSecret(secretStore, *secret, existing, true)
+		mu.Unlock()
+		return
+	}
+
+	resolveAll(secretStore, existing)
+	for _, e := range existing {
+		if config.FindSecret(e.Name) == nil {
+			err = secretStore.Delete(e.Name)
+			globals.PanicOnError(err)
+		}
+	}
+}
+
+var canPrompt bool                                   // set from --interactive
+var nonInteractive bool                              // set from --non-interactive
+var fromFileValues = map[string]string{}             // the values in the --from-file dotenv file
+var decisions *state.State                           // the secrets that the user skipped or never wants to be asked for
+var notPrompted = []string{}                         // the secrets that needed a prompt when there was no terminal
+var missing = []string{}                             // the required secrets that still don't have a value
+var scriptValues = map[string]wrappers.ScriptValue{} // the values that shell scripts returned for other secrets
+
+var trusted *trust.Store // the versions of the manifest and the scripts that the user approved
+var manifestTrusted bool // no script runs unless the manifest that says how to run it is trusted
+
+// what each provider returned, by the name of the provider.  nil if it failed
+var providerResults = map[string]map[string]wrappers.ScriptValue{}
+var providerDone = map[string]chan struct{}{} // closed when the provider has finished
+
+var resolvedValues = map[string]string{} // the values of the secrets that were resolved in this update
 
-	_, err = file.WriteString(toWrite)
+// guards everything above.  it is only unlocked while a script that isn't interactive runs -- see execScript()
+var mu sync.Mutex
+
+/*
+versions before projects kept the secrets of every project in $HOME/.devsecrets.env.  the first time update runs for a
+project, the values of the secrets in the manifest are copied from there, and the lines the user added to it by hand
+are moved to the file of the project (see moveLegacyUnmanaged()).  the secrets are left in the old file because other
+projects may still need them
+*/
+func importLegacySecrets(secretStore store.SecretStore, existing []store.Entry) []store.Entry {
+	if len(existing) != 0 {
+		return existing
+	}
+	if fileBacked, ok := secretStore.(store.FileBacked); ok {
+		if _, err := os.Stat(fileBacked.Files()[0]); err == nil {
+			return existing // the project has been updated before and the secrets were deleted since
+		}
+	}
+	moveLegacyUnmanaged(secretStore)
+	legacy, err := store.New(config.LocalSecrets.Options.Store, config.GetLegacySecretFileName())
+	globals.PanicOnError(err)
+	entries, err := legacy.List()
+	if err != nil {
+		globals.EchoWarning("not copying the secrets from ", legacy.Location(), ": ", err.Error(), "\n")
+		return existing
+	}
+	copied := []string{}
+	for _, e := range entries {
+		if config.FindSecret(e.Name) != nil {
+			globals.PanicOnError(secretStore.Set(e))
+			copied = append(copied, e.Name)
+		}
+	}
+	if len(copied) == 0 {
+		return existing
+	}
+	globals.EchoInfo("copied ", strings.Join(copied, ", "), " from ", legacy.Location(), "\n")
+	existing, err = secretStore.List()
 	globals.PanicOnError(err)
+	return existing
+}
+
+/*
+setup no longer sources $HOME/.devsecrets.env, so the lines the user added to it by hand would stop being loaded.  they
+are moved to the unmanaged section of the file of the project, which env loads like before.  the encrypted store
+can't keep them, so they are left where they are and the user is told to move them
+*/
+func moveLegacyUnmanaged(secretStore store.SecretStore) {
+	legacyFileName := config.GetLegacySecretFileName()
+	legacy, err := envfile.ReadFile(legacyFileName)
+	if err != nil || legacy.Unmanaged == "" {
+		return
+	}
+	fileStore, ok := secretStore.(*store.FileStore)
+	if !ok {
+		globals.EchoWarning("the lines you added to ", legacyFileName, " aren't loaded any more.  move them to the ",
+			"startup file of your shell\n")
+		return
+	}
+	globals.PanicOnError(fileStore.AddUnmanaged(legacy.Unmanaged))
+	legacy.Unmanaged = ""
+	globals.PanicOnError(envfile.WriteFile(legacyFileName, legacy))
+	globals.EchoInfo("moved the lines you added to ", legacyFileName, " to ", fileStore.Location(), "\n")
+}
+
+/*
+resolves every secret in the json.  each secret waits for the secrets it depends on (see config.Dependencies()), and
+secrets that use the terminal also wait for the one before them, so the user is prompted in the order of the json.
+everything else runs at the same time -- e.g. several slow az lookups in scripts that aren't interactive
+*/
+func resolveAll(secretStore store.SecretStore, existing []store.Entry) {
+	order, err := config.ResolutionOrder()
+	globals.PanicOnError(err) // LoadSecretFile() reports a cycle
+
+	done := map[string]chan struct{}{}
+	for _, s := range order {
+		done[s.EnvironmentVariable] = make(chan struct{})
+	}
+	var wg sync.WaitGroup
+	var previousTerminal chan struct{}
+	for _, s := range order {
+		waitFor := []chan struct{}{}
+		for _, dep := range config.Dependencies(s) {
+			waitFor = append(waitFor, done[dep])
+		}
+		if s.UsesTerminal() {
+			if previousTerminal != nil {
+				waitFor = append(waitFor, previousTerminal)
+			}
+			previousTerminal = done[s.EnvironmentVariable]
+		}
+		wg.Add(1)
+		go func(s config.Secret, waitFor []chan struct{}) {
+			defer wg.Done()
+			defer close(done[s.EnvironmentVariable])
+			for _, c := range waitFor {
+				<-c
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			setSecret(secretStore, s, existing, false)
+		}(s, waitFor)
+	}
+	wg.Wait()
+}
+
+// resolves the secret and writes it to the store.  a secret that didn't get resolved is left as it is in the store
+func setSecret(secretStore store.SecretStore, s config.Secret, existing []store.Entry, force bool) {
+	if force {
+		decisions.Clear(s.EnvironmentVariable)
+	}
+	entry, resolved := resolveSecret(s, existing, force)
+	if s.IsRequired() && (!resolved || entry.Value == "") {
+		missing = append(missing, s.EnvironmentVariable)
+	}
+	if !resolved {
+		return
+	}
+	globals.PanicOnError(secretStore.Set(entry))
+	resolvedValues[s.EnvironmentVariable] = entry.Value
+	if entry.Value != "" {
+		decisions.Clear(s.EnvironmentVariable)
+	}
+}
+
+/*
+returns the entry to write for the secret.  unless force is set, a value already in the environment or in the store
+is used as is -- the environment wins if they are different.  otherwise the provider or the shell script is run or
+the user is prompted.  an optional secret that the user skipped is remembered in the store so that they aren't asked
+again.
+
+resolved is false if the secret needed a prompt and update couldn't (or was told not to) prompt for it, or if its
+provider or shell script failed.
+*/
+func resolveSecret(s config.Secret, existing []store.Entry, force bool) (entry store.Entry, resolved bool) {
+	entry = store.Entry{Name: s.EnvironmentVariable, Description: s.Description, Updated: time.Now()}
+	if value, found := fromFileValues[s.EnvironmentVariable]; found && (force || os.Getenv(s.EnvironmentVariable) == "") {
+		entry.Value, entry.Source = value, config.SourceDotenv
+		warnIfInvalid(s, entry)
+		saveToGitHub(entry)
+		return entry, true
+	}
+	// is the value set?
+	if !force {
+		entry.Value = os.Getenv(s.EnvironmentVariable)
+		i := envfile.Find(existing, s.EnvironmentVariable)
+		if i != -1 && existing[i].Expired(time.Now()) && (entry.Value == "" || entry.Value == existing[i].Value) {
+			// the value in the environment was loaded from the store, so it expired as well.  get a new one
+			entry.Value = ""
+		} else if entry.Value == "" && i != -1 && (existing[i].Value != "" || skipped(s, existing[i])) {
+			// the store has a value that this shell never loaded (e.g. update wasn't run from a shell startup file)
+			entry = existing[i]
+			entry.Description = s.Description
+			return entry, true
+		}
+	}
+	if entry.Value == "" {
+		if provider, key := config.FindProvider(s.EnvironmentVariable); provider != nil {
+			value, found := runProvider(*provider, key, existing)
+			if !found {
+				return entry, false
+			}
+			entry.Value, entry.Expires, entry.Source = value.Value, value.Expires, config.SourceProvider
+		} else if value, found := scriptValues[s.EnvironmentVariable]; found {
+			// the shell script of a secret earlier in the json returned this one as well
+			entry.Value, entry.Expires, entry.Source = value.Value, value.Expires, config.SourceShellScript
+		} else if s.ShellScript == "" {
+			if _, found := decisions.Get(s.EnvironmentVariable, time.Now()); found && !nonInteractive {
+				return entry, false
+			}
+			if !canPrompt {
+				notPrompted = append(notPrompted, s.EnvironmentVariable)
+				return entry, false
+			}
+			var decision state.Decision
+			entry.Value, decision = promptForSecret(s)
+			if decision != "" {
+				until := time.Time{}
+				if decision == state.SkipForNow {
+					until = time.Now().Add(config.SkipDuration())
+				}
+				decisions.Set(s.EnvironmentVariable, decision, until)
+				return entry, false
+			}
+			entry.Source = config.SourcePrompt
+			if entry.Value == "" && !s.IsRequired() {
+				entry.Source = config.SourceSkipped
+			}
+		} else if !runScript(s, &entry, existing) {
+			// an empty value would look like the script worked -- leave whatever is in the store alone
+			return entry, false
+		}
+		saveToGitHub(entry)
+	} else if i := envfile.Find(existing, s.EnvironmentVariable); i != -1 && existing[i].Value == entry.Value {
+		// the value was sourced from the env file, so it still came from wherever it came from last time
+		entry.Source = existing[i].Source
+		entry.Updated = existing[i].Updated
+		entry.Expires = existing[i].Expires
+	} else if _, found := wrappers.GetCodespaceSecret(s.EnvironmentVariable); found && config.LocalSecrets.Options.UseGitHubUserSecrets {
+		// GitHub put the user secret into the environment when the Codespace started
+		entry.Source = config.SourceGitHub
+	} else {
+		entry.Source = config.SourceEnvironment
+	}
+	if entry.Source != config.SourcePrompt && entry.Source != config.SourceSkipped {
+		warnIfInvalid(s, entry)
+	}
+	return entry, true
+}
+
+/*
+runs the shell script of the secret and puts its result in entry.  with "output": "json" the result can have values
+for other secrets in the json too -- they are kept in scriptValues for when those secrets are resolved.  returns false
+if the script failed
+*/
+func runScript(s config.Secret, entry *store.Entry, existing []store.Entry) bool {
+	env, err := dependencyEnv(s.DependsOn, existing)
+	var output string
+	if err == nil {
+		output, err = execScript(wrappers.Script{Path: s.ShellScript, Args: s.Args, Dir: s.ScriptDir(),
+			Timeout: s.ScriptTimeout(), Env: env, Interactive: s.IsInteractive()})
+	}
+	if err == nil && s.Output == config.ScriptOutputJSON {
+		var values map[string]wrappers.ScriptValue
+		if values, err = wrappers.ParseScriptJSON(output); err == nil {
+			value, found := values[s.EnvironmentVariable]
+			if !found {
+				err = fmt.Errorf("the JSON it returned doesn't have a value for %s", s.EnvironmentVariable)
+			}
+			output, entry.Expires = value.Value, value.Expires
+			for name, value := range values {
+				if config.FindSecret(name) == nil {
+					globals.EchoWarning(s.ShellScript, " returned ", name, ", which is not in ", config.Value("input-file"), "\n")
+				} else if name != s.EnvironmentVariable {
+					scriptValues[name] = value
+				}
+			}
+		}
+	}
+	if err != nil {
+		globals.EchoError(s.EnvironmentVariable, ": ", s.ShellScript, " failed: ", err.Error(), "\n")
+		return false
+	}
+	entry.Value, entry.Source = output, config.SourceShellScript
+	return true
+}
+
+/*
+returns the value for key that the provider returned.  the provider is only run the first time one of its secrets
+needs a value -- after that (even if it failed) what it returned is reused.  found is false if the provider failed or
+didn't return the key
+*/
+func runProvider(provider config.Provider, key string, existing []store.Entry) (value wrappers.ScriptValue, found bool) {
+	values, ran := providerResults[provider.Name]
+	if done, running := providerDone[provider.Name]; !ran && running {
+		// another secret of the provider started it
+		mu.Unlock()
+		<-done
+		mu.Lock()
+		values = providerResults[provider.Name]
+	} else if !ran {
+		done = make(chan struct{})
+		providerDone[provider.Name] = done
+		env, err := dependencyEnv(provider.DependsOn, existing)
+		var output string
+		if err == nil {
+			output, err = execScript(wrappers.Script{Path: provider.ShellScript, Args: provider.Args, Dir: provider.ScriptDir(),
+				Timeout: provider.ScriptTimeout(), Env: env, Interactive: provider.IsInteractive()})
+		}
+		if err == nil {
+			values, err = wrappers.ParseScriptJSON(output)
+		}
+		if err != nil {
+			globals.EchoError("provider ", provider.Name, ": ", provider.ShellScript, " failed: ", err.Error(), "\n")
+		}
+		providerResults[provider.Name] = values
+		close(done)
+	}
+	if values == nil {
+		return
+	}
+	if value, found = values[key]; !found {
+		globals.EchoError("provider ", provider.Name, " did not return ", key, " for ", provider.Outputs[key], "\n")
+	}
+	return
+}
+
+/*
+runs a shell script.  a script that isn't interactive runs with mu unlocked so that other secrets are resolved while
+it runs.  an interactive one keeps the lock, and with it the terminal
+*/
+func execScript(script wrappers.Script) (string, error) {
+	if err := checkTrust(script.Path); err != nil {
+		return "", err
+	}
+	if !script.Interactive {
+		mu.Unlock()
+		defer mu.Lock()
+	}
+	return wrappers.ExecBash(script)
+}
+
+/*
+asks the user to approve the manifest and the scripts if they are new or changed since they were approved.  without
+a terminal nobody can approve them, so the scripts don't run and the user is told how to approve them.  a manifest
+without scripts doesn't run anything, so there is nothing to approve
+*/
+func reviewTrust() {
+	if len(config.ScriptFiles()) == 0 {
+		return
+	}
+	var err error
+	trusted, err = trust.Load(config.GetTrustFileName(), config.GetConfigLockFileName())
+	if err != nil {
+		globals.EchoError("error reading " + config.GetTrustFileName() + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+	inputFile := config.Value("input-file")
+	untrusted, err := trust.Review(trusted, append([]string{inputFile}, config.ScriptFiles()...), canPrompt)
+	if err != nil {
+		globals.EchoError("error checking if the scripts are trusted: ", err.Error(), "\n")
+		os.Exit(2)
+	}
+	manifestTrusted = trusted.IsTrusted(inputFile)
+	if len(untrusted) != 0 {
+		globals.EchoWarning("devsecrets: not running scripts from files that changed or were never approved: ",
+			strings.Join(untrusted, ", "), ".  run 'devsecrets allow --input-file ", inputFile, "' to review them\n")
+	}
+}
+
+// returns an error if the script, or the manifest that runs it, isn't the version the user approved
+func checkTrust(script string) error {
+	if !manifestTrusted {
+		return fmt.Errorf("%s is not trusted", config.Value("input-file"))
+	}
+	change, err := trusted.Check(script)
+	if err != nil {
+		return err
+	}
+	if change != nil {
+		return fmt.Errorf("%s is not trusted", change.FileName)
+	}
+	return nil
+}
+
+/*
+returns NAME=value for each of the secrets, for the environment of a script that depends on them.  the value is the
+one resolved in this update or, with --name, the one in the environment or the store
+*/
+func dependencyEnv(names []string, existing []store.Entry) (env []string, err error) {
+	for _, name := range names {
+		value, found := resolvedValues[name]
+		if !found {
+			value = os.Getenv(name)
+		}
+		if i := envfile.Find(existing, name); !found && value == "" && i != -1 {
+			value = existing[i].Value
+		}
+		if value == "" {
+			return nil, fmt.Errorf("it depends on %s, which doesn't have a value", name)
+		}
+		env = append(env, name+"="+value)
+	}
+	return
+}
+
+// returns true if the entry records that the user skipped the secret.  if it has since become required, ask again
+func skipped(s config.Secret, e store.Entry) bool {
+	return e.Source == config.SourceSkipped && !s.IsRequired()
+}
+
+/*
+prompts until the value passes the rules in devsecrets.json.  hitting enter takes the default, or skips an optional
+secret.  hitting enter for a required secret asks if the user wants to skip it for now or never be asked for it, in
+which case the decision is returned instead of a value.
+
+when the input isn't a terminal there is nobody to re-ask, so an invalid value is thrown away and the secret
+is left empty -- "devsecrets verify" will report it
+*/
+func promptForSecret(s config.Secret) (string, state.Decision) {
+	prompt := fmt.Sprint("Enter value for ", s.EnvironmentVariable)
+	if s.Default != "" {
+		prompt += fmt.Sprint(" [", s.Default, "]")
+	} else if !s.IsRequired() {
+		prompt += " (optional, hit enter to skip)"
+	}
+	prompt += ": "
+	for {
+		var value string
+		if s.IsMasked() {
+			value = globals.EnterSecret(prompt, config.LocalSecrets.Options.ShowStars)
+		} else {
+			value = globals.EnterString(prompt)
+		}
+		if value == "" && s.Default != "" {
+			value = s.Default
+		}
+		if value == "" && !s.IsRequired() {
+			return "", ""
+		}
+		if value == "" && globals.StdinIsTerminal() {
+			if decision := askToSkip(s); decision != "" {
+				return "", decision
+			}
+			continue
+		}
+		validity, reason := s.Validate(value)
+		if value == "" {
+			validity, reason = config.Invalid, "is required"
+		}
+		if validity != config.Invalid {
+			return value, ""
+		}
+		globals.EchoError(s.EnvironmentVariable, " ", reason, "\n")
+		if !globals.StdinIsTerminal() {
+			return "", ""
+		}
+	}
+}
+
+// asks what to do about a required secret that the user didn't enter.  returns "" to ask for the value again
+func askToSkip(s config.Secret) state.Decision {
+	until := time.Now().Add(config.SkipDuration()).Format("Jan 2 15:04")
+	prompt := fmt.Sprint(s.EnvironmentVariable, " is required.  hit enter to type it in, [s] to skip it until ", until,
+		" or [n] to never ask for it again: ")
+	switch strings.ToLower(strings.TrimSpace(globals.EnterString(prompt))) {
+	case "s":
+		return state.SkipForNow
+	case "n":
+		return state.NeverAsk
+	}
+	return ""
+}
+
+// prints one line with the secrets that update couldn't prompt for, so that a terminal without input isn't blocked
+func warnNotPrompted() {
+	if len(notPrompted) == 0 || nonInteractive { // --non-interactive reports the missing secrets itself
+		return
+	}
+	globals.EchoWarning("devsecrets: not prompting without a terminal, these secrets are missing: ",
+		strings.Join(notPrompted, ", "), ".  run 'devsecrets update' in a terminal to set them\n")
+}
+
+// values that didn't come from a prompt can't be re-asked for, but the user should know that they are wrong
+func warnIfInvalid(s config.Secret, entry store.Entry) {
+	if validity, reason := s.Validate(entry.Value); validity == config.Invalid {
+		globals.EchoWarning(fmt.Sprint(s.EnvironmentVariable, " from ", entry.Source, " ", reason, "\n"))
+	}
+}
+
+var gitHubRepo string // the repo that user secrets are scoped to. looked up once per update
+
+/*
+when useGitHubUserSecrets is set, a newly resolved value is also saved as a GitHub user secret so that it is in the
+environment of every Codespace for this repo and the user isn't prompted for it again
+*/
+func saveToGitHub(entry store.Entry) {
+	if !config.LocalSecrets.Options.UseGitHubUserSecrets || entry.Value == "" {
+		return
+	}
+	var err error
+	if gitHubRepo == "" {
+		gitHubRepo, err = wrappers.GitHubRepository()
+	}
+	if err == nil {
+		err = wrappers.SetGitHubUserSecret(entry.Name, entry.Value, gitHubRepo)
+	}
+	if err != nil {
+		globals.EchoError("unable to save ", entry.Name, " as a GitHub user secret: ", err.Error(), "\n")
+	}
 }
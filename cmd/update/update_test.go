@@ -0,0 +1,200 @@
+package update
+
+import (
+	"devsecrets/config"
+	"devsecrets/envfile"
+	"devsecrets/state"
+	"devsecrets/store"
+	"devsecrets/trust"
+	"devsecrets/wrappers"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+writes the scripts to a temporary directory, trusts them and resets what update remembers between secrets.  returns
+the directory and a store in it
+*/
+func setupResolve(t *testing.T, scripts map[string]string) (string, store.SecretStore) {
+	dir := t.TempDir()
+	var err error
+	trusted, err = trust.Load(filepath.Join(dir, "trust.json"), filepath.Join(dir, "config.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range scripts {
+		path := filepath.Join(dir, name)
+		if err = os.WriteFile(path, []byte("#!/bin/bash\n"+content), 0755); err != nil {
+			t.Fatal(err)
+		}
+		change, _ := trusted.Check(path)
+		trusted.Approve(*change)
+	}
+	manifestTrusted = true
+	decisions = &state.State{FileName: filepath.Join(dir, "state.json"), Secrets: map[string]state.Record{}}
+	canPrompt, nonInteractive = false, true
+	missing, notPrompted = []string{}, []string{}
+	scriptValues = map[string]wrappers.ScriptValue{}
+	providerResults = map[string]map[string]wrappers.ScriptValue{}
+	providerDone = map[string]chan struct{}{}
+	resolvedValues = map[string]string{}
+	t.Cleanup(func() { config.LocalSecrets = config.DevSecrets{} })
+
+	secretStore, err := store.New(store.DefaultStore, filepath.Join(dir, "secrets.env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, secretStore
+}
+
+// returns the value of each secret in the store
+func storedValues(t *testing.T, secretStore store.SecretStore) map[string]string {
+	entries, err := secretStore.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{}
+	for _, e := range entries {
+		values[e.Name] = e.Value
+	}
+	return values
+}
+
+func TestScriptsThatArentInteractiveRunAtTheSameTime(t *testing.T) {
+	// each script waits (for up to 5 seconds) until the other one has started
+	wait := `touch "$1.started"
+for i in $(seq 50); do [ -f "$2.started" ] && echo "$1" && exit 0; sleep 0.1; done
+exit 1
+`
+	dir, secretStore := setupResolve(t, map[string]string{"wait.sh": wait})
+	no := false
+	script := filepath.Join(dir, "wait.sh")
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	config.LocalSecrets.Secrets = []config.Secret{
+		{EnvironmentVariable: "DEVSECRETS_TEST_A", ShellScript: script, Args: []string{a, b}, Interactive: &no},
+		{EnvironmentVariable: "DEVSECRETS_TEST_B", ShellScript: script, Args: []string{b, a}, Interactive: &no},
+	}
+	resolveAll(secretStore, nil)
+
+	values := storedValues(t, secretStore)
+	if values["DEVSECRETS_TEST_A"] != a || values["DEVSECRETS_TEST_B"] != b {
+		t.Errorf("the scripts didn't run at the same time: %v", values)
+	}
+}
+
+func TestProviderRunsOnce(t *testing.T) {
+	provider := `echo run >> "$1"
+echo '{"appId": "app", "password": "pw"}'
+`
+	dir, secretStore := setupResolve(t, map[string]string{"provider.sh": provider})
+	no := false
+	runs := filepath.Join(dir, "runs")
+	config.LocalSecrets.Secrets = []config.Secret{
+		{EnvironmentVariable: "DEVSECRETS_TEST_CLIENT_ID"},
+		{EnvironmentVariable: "DEVSECRETS_TEST_CLIENT_SECRET"},
+	}
+	config.LocalSecrets.Providers = []config.Provider{{Name: "sp", ShellScript: filepath.Join(dir, "provider.sh"),
+		Args: []string{runs}, Interactive: &no,
+		Outputs: map[string]string{"appId": "DEVSECRETS_TEST_CLIENT_ID", "password": "DEVSECRETS_TEST_CLIENT_SECRET"}}}
+	resolveAll(secretStore, nil)
+
+	values := storedValues(t, secretStore)
+	if values["DEVSECRETS_TEST_CLIENT_ID"] != "app" || values["DEVSECRETS_TEST_CLIENT_SECRET"] != "pw" {
+		t.Errorf("the provider's values weren't stored: %v", values)
+	}
+	if bytes, _ := os.ReadFile(runs); strings.Count(string(bytes), "run") != 1 {
+		t.Errorf("the provider ran %d times, want once", strings.Count(string(bytes), "run"))
+	}
+}
+
+func TestScriptGetsDependsOn(t *testing.T) {
+	dir, secretStore := setupResolve(t, map[string]string{"token.sh": `echo "token-for-$DEVSECRETS_TEST_TENANT"` + "\n"})
+	no := false
+	config.LocalSecrets.Secrets = []config.Secret{
+		{EnvironmentVariable: "DEVSECRETS_TEST_TOKEN", ShellScript: filepath.Join(dir, "token.sh"), Interactive: &no,
+			DependsOn: []string{"DEVSECRETS_TEST_TENANT"}},
+		{EnvironmentVariable: "DEVSECRETS_TEST_TENANT"},
+	}
+	existing := []store.Entry{{Name: "DEVSECRETS_TEST_TENANT", Value: "contoso"}}
+	if err := envfile.WriteFile(filepath.Join(dir, "secrets.env"), envfile.File{Entries: existing}); err != nil {
+		t.Fatal(err)
+	}
+	resolveAll(secretStore, existing)
+
+	if value := storedValues(t, secretStore)["DEVSECRETS_TEST_TOKEN"]; value != "token-for-contoso" {
+		t.Errorf("DEVSECRETS_TEST_TOKEN = %q, want token-for-contoso", value)
+	}
+}
+
+func TestLinesAddedToLegacyFileAreMoved(t *testing.T) {
+	dir, secretStore := setupResolve(t, nil)
+	t.Setenv("HOME", dir)
+	config.LocalSecrets.Secrets = []config.Secret{{EnvironmentVariable: "DEVSECRETS_TEST_PAT"}}
+	legacy := envfile.File{Entries: []store.Entry{{Name: "DEVSECRETS_TEST_PAT", Value: "pat"}}, Unmanaged: "alias k=kubectl"}
+	if err := envfile.WriteFile(config.GetLegacySecretFileName(), legacy); err != nil {
+		t.Fatal(err)
+	}
+	importLegacySecrets(secretStore, nil)
+
+	if value := storedValues(t, secretStore)["DEVSECRETS_TEST_PAT"]; value != "pat" {
+		t.Errorf("DEVSECRETS_TEST_PAT = %q, want pat", value)
+	}
+	if unmanaged, _ := secretStore.(*store.FileStore).Unmanaged(); unmanaged != "alias k=kubectl" {
+		t.Errorf("the unmanaged lines of the project = %q, want alias k=kubectl", unmanaged)
+	}
+	if legacy, _ = envfile.ReadFile(config.GetLegacySecretFileName()); legacy.Unmanaged != "" || len(legacy.Entries) != 1 {
+		t.Errorf("the legacy file = %+v, want just the secret", legacy)
+	}
+}
+
+// sets a setting the way a flag would, and puts it back to empty when the test ends
+func setSetting(t *testing.T, name string, value string) {
+	if _, err := config.AddSetting(name, value, ""); err != nil {
+		config.FindSettingByName(name).SetValue(value)
+	}
+	t.Cleanup(func() { config.FindSettingByName(name).SetValue("") })
+}
+
+// --name resolves the secret again even though it has a value, and leaves the other entries in the store alone
+func TestUpdateName(t *testing.T) {
+	dir, _ := setupResolve(t, nil)
+	t.Setenv("HOME", dir)
+	manifest := filepath.Join(dir, "devsecrets.json")
+	os.WriteFile(manifest, []byte("{}"), 0644)
+	setSetting(t, "input-file", manifest)
+	setSetting(t, "name", "DEVSECRETS_TEST_PAT")
+	setSetting(t, "all", "false")
+	config.LocalSecrets.Secrets = []config.Secret{
+		{EnvironmentVariable: "DEVSECRETS_TEST_PAT"},
+		{EnvironmentVariable: "DEVSECRETS_TEST_TENANT"},
+	}
+	fromFileValues = map[string]string{"DEVSECRETS_TEST_PAT": "new", "DEVSECRETS_TEST_TENANT": "new"}
+	t.Cleanup(func() { fromFileValues = map[string]string{} })
+
+	updated := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	before := []store.Entry{
+		{Name: "DEVSECRETS_TEST_PAT", Value: "old", Source: config.SourcePrompt, Updated: updated},
+		{Name: "DEVSECRETS_TEST_TENANT", Value: "contoso", Description: "the tenant", Source: config.SourcePrompt, Updated: updated},
+	}
+	if err := envfile.WriteFile(config.GetSecretFileName(), envfile.File{Entries: before}); err != nil {
+		t.Fatal(err)
+	}
+	updateSecrets()
+
+	after, err := envfile.Read(config.GetSecretFileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i := envfile.Find(after, "DEVSECRETS_TEST_PAT"); i == -1 || after[i].Value != "new" {
+		t.Errorf("DEVSECRETS_TEST_PAT wasn't resolved again: %+v", after)
+	}
+	if i := envfile.Find(after, "DEVSECRETS_TEST_TENANT"); i == -1 || after[i] != before[1] {
+		t.Errorf("DEVSECRETS_TEST_TENANT changed: %+v, want %+v", after, before[1])
+	}
+	if len(after) != len(before) {
+		t.Errorf("the store has %d entries, want %d", len(after), len(before))
+	}
+}
@@ -13,6 +13,14 @@ var UpdateCmd = &cobra.Command{
 	Short: "applies all the secrets in input-file.  does not delete old secrets. Prompts on empty secrets.",
 	Long: ` 
 	devsecrets update --all | --name <name> --verbose --input-file dev-secrets.json
+
+	--all (the default) resolves every secret that doesn't have a value yet
+	--name re-resolves only the named secret, even if it already has a value
+	--interactive auto (the default) prompts only if stdin is a terminal, always prompts even
+	  if it isn't (e.g. to pipe the values in) and never doesn't prompt at all
+	--non-interactive (or DEVSECRETS_NONINTERACTIVE=true) never prompts, and exits with 3 and
+	  prints {"missing": [...]} if a required secret doesn't have a value.  for CI and prebuilds
+	--from-file takes the values from a dotenv file.  the environment still wins
     
     `,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -31,4 +39,17 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// repoCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	UpdateCmd.Flags().String("interactive", "auto", "when to prompt for missing secrets: auto, always or never")
+	UpdateCmd.Flags().Bool("non-interactive", false, "never prompt and exit with 3 if a required secret is missing")
+	UpdateCmd.Flags().String("from-file", "", "a dotenv file with values for the secrets")
+}
+
+/*
+returns true if update was run with --non-interactive (or DEVSECRETS_NONINTERACTIVE).  the root command calls this
+before it loads the config, because stdout has to be nothing but the {"missing": [...]} JSON from the very first line
+*/
+func IsNonInteractive(cmd *cobra.Command) bool {
+	flag, _ := cmd.Flags().GetBool("non-interactive")
+	// viper also binds DEVSECRETS_NON_INTERACTIVE to the flag, but only once the config is loaded
+	return flag || envIsTrue(NonInteractiveEnvVar) || envIsTrue("DEVSECRETS_NON_INTERACTIVE")
 }
@@ -1,12 +1,189 @@
 package verify
 
 import (
-
+	"devsecrets/config"
+	"devsecrets/envfile"
 	"devsecrets/globals"
+	"devsecrets/store"
+	"fmt"
+	"os"
+	"time"
+)
 
+// the state of one secret as reported by verify
+const (
+	statusSet     = "set"
+	statusEmpty   = "empty"
+	statusMissing = "missing"
+	statusSkipped = "skipped" // an optional secret that the user chose not to set
+	statusExpired = "expired" // the shell script said when the value stops working, and it has
 )
+
+// one row in the health report
+type verifyResult struct {
+	Name     string
+	Required bool
+	Status   string
+	Source   string
+	Updated  string
+	Valid    config.Validation // checked against the rules in devsecrets.json
+	Reason   string            // why the value is invalid
+}
+
+/*
+arrived via 'devsecrets verify'
+compares every secret in the manifest against the store and the environment of the current process and prints a
+table of the results.  exits with globals.ExitMissingSecrets if any required secret does not have a value so that
+the postCreateCommand or a CI step can gate on it.  optional secrets are reported, but they never fail verify.
+*/
 func onVerify() {
-	globals.EchoInfo("\nRunning Verify Portal Config\n")
+	config.LoadSecretFile()
+	name := config.Value("name")
+
+	secretStore, err := store.FromConfig()
+	if err != nil {
+		globals.EchoError("Error loading config: ", err.Error(), "\n")
+		os.Exit(2)
+	}
+	entries, err := secretStore.List()
+	if err != nil {
+		globals.EchoError("error reading " + secretStore.Location() + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+
+	results := []verifyResult{}
+	for _, s := range config.LocalSecrets.Secrets {
+		if name != "" && s.EnvironmentVariable != name {
+			continue
+		}
+		results = append(results, verifySecret(s, entries))
+	}
+	if name != "" && len(results) == 0 {
+		globals.EchoError(name, " is not in ", config.Value("input-file"), "\n")
+		os.Exit(2)
+	}
+
+	printResults(results)
+
+	missing, invalid := 0, 0
+	for _, r := range results {
+		if r.Status != statusSet && r.Required {
+			missing++
+		}
+		if r.Valid == config.Invalid {
+			invalid++
+			globals.EchoError(r.Name, " ", r.Reason, "\n")
+		}
+	}
+	if missing > 0 {
+		globals.EchoError(fmt.Sprint(missing, " required secret(s) do not have a value. run 'devsecrets update' to set them\n"))
+		os.Exit(globals.ExitMissingSecrets)
+	}
+	if invalid > 0 {
+		globals.EchoError(fmt.Sprint(invalid, " secret(s) have an invalid value. run 'devsecrets update --name <name>' to fix them\n"))
+		os.Exit(globals.ExitInvalidSecrets)
+	}
+}
+
+/*
+a secret is set if it has a value in either the store or the environment.  the environment wins because that is
+what a process started from this shell will see -- unless it is the expired value that the shell loaded from the
+store.  a value that is set is also checked against the rules for the secret.
+*/
+func verifySecret(s config.Secret, entries []store.Entry) (result verifyResult) {
+	result = verifyResult{Name: s.EnvironmentVariable, Required: s.IsRequired(), Status: statusMissing, Source: "-", Updated: "-", Valid: config.Unknown}
+	value := ""
+	if i := envfile.Find(entries, s.EnvironmentVariable); i != -1 {
+		e := entries[i]
+		value = e.Value
+		result.Status = statusEmpty
+		if e.Value != "" {
+			result.Status = statusSet
+		} else if e.Source == config.SourceSkipped {
+			result.Status = statusSkipped
+		}
+		if e.Value != "" && e.Expired(time.Now()) {
+			result.Status = statusExpired
+		}
+		if e.Source != "" {
+			result.Source = e.Source
+		}
+		if !e.Updated.IsZero() {
+			result.Updated = e.Updated.Local().Format("2006-01-02 15:04:05")
+		}
+	}
 
-	
+	if val, found := os.LookupEnv(s.EnvironmentVariable); found && (result.Status != statusExpired || val != value) {
+		if val != "" {
+			result.Status = statusSet
+			value = val
+		} else if result.Status == statusMissing {
+			result.Status = statusEmpty
+		}
+		if result.Source == "-" {
+			result.Source = config.SourceEnvironment
+		}
+	}
+	if result.Status == statusSet {
+		result.Valid, result.Reason = s.Validate(value)
+	}
+	return
+}
+
+func printResults(results []verifyResult) {
+	header := []string{"Number", "Environment Variable", "Required", "Status", "Source", "Last Updated", "Valid"}
+	toPrint := make([]globals.IConsolePrint, len(results))
+	for i, r := range results {
+		toPrint[i] = r
+	}
+	globals.PrintTable(header, toPrint)
+}
+
+// implement IConsolePrint for verifyResult
+func (r verifyResult) ColumnCount() int {
+	return 7
+}
+func (r verifyResult) Cell(row int, column int) string {
+	switch column {
+	case 0:
+		return fmt.Sprint(row)
+	case 1:
+		return r.Name
+	case 2:
+		if r.Required {
+			return "yes"
+		}
+		return "no"
+	case 3:
+		return r.Status
+	case 4:
+		return r.Source
+	case 5:
+		return r.Updated
+	case 6:
+		return string(r.Valid)
+	default:
+		panic("Bad column index passed in")
+	}
+}
+func (r verifyResult) CellColor(row int, col int) string {
+	if r.Valid == config.Invalid {
+		return globals.ColorRed
+	}
+	switch {
+	case r.Status == statusSet:
+		return globals.ColorGreen
+	case r.Status == statusEmpty || !r.Required:
+		return globals.ColorYellow
+	default:
+		return globals.ColorRed
+	}
+}
+func (r verifyResult) FillChar(row int, col int) string {
+	switch col {
+	case 0, 1:
+		return "."
+	default:
+		return " "
+	}
 }
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+returns the secrets that have to be resolved before the secret: its dependsOn, the dependsOn of its provider and the
+secrets before it in the json whose shell script returns JSON -- that script might return a value for this secret too.
+see dependencyGraph() for when an earlier JSON script is left out
+*/
+func Dependencies(s Secret) []string {
+	return dependencyGraph()[s.EnvironmentVariable]
+}
+
+// the dependsOn of the secret and the dependsOn of its provider
+func explicitDependencies(s Secret) (names []string) {
+	names = append(names, s.DependsOn...)
+	if provider, _ := FindProvider(s.EnvironmentVariable); provider != nil {
+		names = append(names, provider.DependsOn...)
+	}
+	return
+}
+
+/*
+returns the dependencies of every secret.  a secret only depends on an earlier JSON script if that script doesn't
+already (through its dependsOn) need the secret: a script that dependsOn TENANT can't return the value of TENANT, so
+waiting for it would be a cycle that isn't in the manifest.  the hidden dependencies are added in the order of the
+json, and each one is only added if it can't close a cycle with the ones before it
+*/
+func dependencyGraph() map[string][]string {
+	graph := map[string][]string{}
+	for _, s := range LocalSecrets.Secrets {
+		graph[s.EnvironmentVariable] = explicitDependencies(s)
+	}
+	for i, s := range LocalSecrets.Secrets {
+		for _, earlier := range LocalSecrets.Secrets[:i] {
+			if earlier.ShellScript != "" && earlier.Output == ScriptOutputJSON &&
+				!reaches(graph, earlier.EnvironmentVariable, s.EnvironmentVariable, map[string]bool{}) {
+				graph[s.EnvironmentVariable] = append(graph[s.EnvironmentVariable], earlier.EnvironmentVariable)
+			}
+		}
+	}
+	return graph
+}
+
+// returns true if from depends on to, directly or through other secrets
+func reaches(graph map[string][]string, from string, to string, seen map[string]bool) bool {
+	if from == to {
+		return true
+	}
+	if seen[from] {
+		return false
+	}
+	seen[from] = true
+	for _, dep := range graph[from] {
+		if reaches(graph, dep, to, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+returns true if resolving the secret can use the terminal: it has no script, so the user is prompted for it, or its
+shell script or provider is interactive.  these secrets are resolved one at a time
+*/
+func (s Secret) UsesTerminal() bool {
+	if provider, _ := FindProvider(s.EnvironmentVariable); provider != nil {
+		return provider.IsInteractive()
+	}
+	return s.ShellScript == "" || s.IsInteractive()
+}
+
+/*
+returns the secrets in the order they can be resolved in: every secret comes after the secrets it depends on, and
+otherwise the order of the json is kept.  returns an error that names the cycle if the secrets depend on each other
+*/
+func ResolutionOrder() (order []Secret, err error) {
+	placed := map[string]bool{}
+	for len(order) < len(LocalSecrets.Secrets) {
+		next := -1
+		for i, s := range LocalSecrets.Secrets {
+			if !placed[s.EnvironmentVariable] && allPlaced(Dependencies(s), placed) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			return nil, fmt.Errorf("these secrets depend on each other: %s", strings.Join(findCycle(placed), " -> "))
+		}
+		order = append(order, LocalSecrets.Secrets[next])
+		placed[LocalSecrets.Secrets[next].EnvironmentVariable] = true
+	}
+	return
+}
+
+func allPlaced(names []string, placed map[string]bool) bool {
+	for _, name := range names {
+		if !placed[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// follows the dependencies of the secrets that couldn't be placed until one comes around again
+func findCycle(placed map[string]bool) (cycle []string) {
+	seen := map[string]int{} // the index in cycle
+	for _, s := range LocalSecrets.Secrets {
+		if !placed[s.EnvironmentVariable] {
+			cycle = []string{s.EnvironmentVariable}
+			break
+		}
+	}
+	for {
+		name := cycle[len(cycle)-1]
+		if i, found := seen[name]; found {
+			return cycle[i:]
+		}
+		seen[name] = len(cycle) - 1
+		for _, dep := range Dependencies(*FindSecret(name)) {
+			if !placed[dep] {
+				cycle = append(cycle, dep)
+				break
+			}
+		}
+	}
+}
+
+// makes sure that every dependsOn names a secret in the manifest and that the secrets don't depend on each other
+func CheckDependencies() error {
+	for _, s := range LocalSecrets.Secrets {
+		for _, dep := range s.DependsOn {
+			if FindSecret(dep) == nil {
+				return fmt.Errorf("%s: dependsOn %s, which is not in the secrets", s.EnvironmentVariable, dep)
+			}
+		}
+	}
+	for _, p := range LocalSecrets.Providers {
+		for _, dep := range p.DependsOn {
+			if FindSecret(dep) == nil {
+				return fmt.Errorf("provider %s: dependsOn %s, which is not in the secrets", p.Name, dep)
+			}
+		}
+	}
+	_, err := ResolutionOrder()
+	return err
+}
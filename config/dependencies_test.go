@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolutionOrder(t *testing.T) {
+	defer func() { LocalSecrets = DevSecrets{} }()
+	LocalSecrets.Secrets = []Secret{
+		{EnvironmentVariable: "AZURE_TOKEN", ShellScript: "./token.sh", DependsOn: []string{"AZURE_SUB_ID", "AZURE_TENANT_ID"}},
+		{EnvironmentVariable: "AZURE_SUB_ID"},
+		{EnvironmentVariable: "AZURE_TENANT_ID", ShellScript: "./tenant.sh", DependsOn: []string{"AZURE_SUB_ID"}},
+		{EnvironmentVariable: "GITLAB_PAT"},
+	}
+	if err := CheckDependencies(); err != nil {
+		t.Fatalf("CheckDependencies() error = %v", err)
+	}
+	order, _ := ResolutionOrder()
+	got := []string{}
+	for _, s := range order {
+		got = append(got, s.EnvironmentVariable)
+	}
+	want := "AZURE_SUB_ID AZURE_TENANT_ID AZURE_TOKEN GITLAB_PAT"
+	if strings.Join(got, " ") != want {
+		t.Errorf("ResolutionOrder() = %v, want %v", got, want)
+	}
+
+	LocalSecrets.Secrets[1].DependsOn = []string{"AZURE_TOKEN"}
+	err := CheckDependencies()
+	if err == nil || !strings.Contains(err.Error(), "AZURE_TOKEN -> AZURE_SUB_ID -> AZURE_TOKEN") {
+		t.Errorf("CheckDependencies() of a cycle = %v", err)
+	}
+
+	LocalSecrets.Secrets[1].DependsOn = []string{"NOT_A_SECRET"}
+	if err = CheckDependencies(); err == nil {
+		t.Error("CheckDependencies() of an unknown secret didn't return an error")
+	}
+}
+
+func TestUsesTerminal(t *testing.T) {
+	defer func() { LocalSecrets = DevSecrets{} }()
+	no := false
+	LocalSecrets.Secrets = []Secret{
+		{EnvironmentVariable: "PROMPTED"},
+		{EnvironmentVariable: "INTERACTIVE_SCRIPT", ShellScript: "./script.sh"},
+		{EnvironmentVariable: "SCRIPT", ShellScript: "./script.sh", Interactive: &no},
+		{EnvironmentVariable: "PROVIDED"},
+	}
+	LocalSecrets.Providers = []Provider{{Name: "p", ShellScript: "./p.sh", Interactive: &no, Outputs: map[string]string{"x": "PROVIDED"}}}
+	want := []bool{true, true, false, false}
+	for i, s := range LocalSecrets.Secrets {
+		if s.UsesTerminal() != want[i] {
+			t.Errorf("%s.UsesTerminal() = %v, want %v", s.EnvironmentVariable, s.UsesTerminal(), want[i])
+		}
+	}
+}
+
+func TestJSONScriptDependsOnLaterSecret(t *testing.T) {
+	defer func() { LocalSecrets = DevSecrets{} }()
+	LocalSecrets.Secrets = []Secret{
+		{EnvironmentVariable: "AZURE_SUB_ID", ShellScript: "./sub.sh", Output: ScriptOutputJSON, DependsOn: []string{"AZURE_TENANT_ID"}},
+		{EnvironmentVariable: "AZURE_TENANT_ID"},
+		{EnvironmentVariable: "AZURE_REGION"},
+	}
+	if err := CheckDependencies(); err != nil {
+		t.Fatalf("CheckDependencies() error = %v", err)
+	}
+	order, _ := ResolutionOrder()
+	got := []string{}
+	for _, s := range order {
+		got = append(got, s.EnvironmentVariable)
+	}
+	// AZURE_REGION still waits for the JSON script, which might return it
+	want := "AZURE_TENANT_ID AZURE_SUB_ID AZURE_REGION"
+	if strings.Join(got, " ") != want {
+		t.Errorf("ResolutionOrder() = %v, want %v", got, want)
+	}
+}
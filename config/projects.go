@@ -0,0 +1,135 @@
+package config
+
+import (
+	"crypto/sha256"
+	"devsecrets/projects"
+	"devsecrets/wrappers"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+/*
+every project keeps its secrets apart from the other projects, so that two repos with a devsecrets.json in one
+container (or on a shared dev VM) don't overwrite each other's secrets.  the project is named by "project" in
+devsecrets.json:
+
+	{
+	    "project": "contoso-app",
+	    "secrets": [ ... ]
+	}
+
+or, if there isn't one, after the directory and the path of devsecrets.json (e.g. "app-3f9a1c2e").  manifests with
+the same "project" share their secrets.  the files of a project are in $HOME/.config/devsecrets/projects/<name>/
+*/
+var projectNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// the characters of a directory name that can't be in the name of a project
+var notInProjectName = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// returns the name of the project of the manifest that was loaded with LoadSecretFile()
+func ProjectName() string {
+	if LocalSecrets.Project != "" {
+		return LocalSecrets.Project
+	}
+	return DefaultProjectName(Value("input-file"))
+}
+
+// the name of a project whose manifest doesn't have a "project": the name of its directory and a hash of its path
+func DefaultProjectName(manifest string) string {
+	if abs, err := filepath.Abs(manifest); err == nil {
+		manifest = abs
+	}
+	sum := sha256.Sum256([]byte(manifest))
+	dir := strings.TrimLeft(notInProjectName.ReplaceAllString(filepath.Base(filepath.Dir(manifest)), "_"), ".")
+	return dir + "-" + hex.EncodeToString(sum[:4])
+}
+
+// makes sure that "project" can be the name of a directory
+func CheckProject() error {
+	if p := LocalSecrets.Project; p != "" && !projectNamePattern.MatchString(p) {
+		return fmt.Errorf("project \"%s\" can only have letters, digits, '.', '_' and '-', and can't start with '.' or '-'", p)
+	}
+	return nil
+}
+
+// returns $HOME/.config/devsecrets/projects/<name>.  the directory is created if it doesn't exist
+func GetProjectDir(name string) string {
+	dir := filepath.Join(GetConfigDir(), "projects", name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		panic(err)
+	}
+	return dir
+}
+
+// the env file of the project.  the stores keep the secrets in it, or next to it (see the store package)
+func ProjectSecretFileName(name string) string {
+	return filepath.Join(GetProjectDir(name), "secrets.env")
+}
+
+// the decisions about the secrets of the project.  see the state package
+func ProjectStateFileName(name string) string {
+	return filepath.Join(GetProjectDir(name), "state.json")
+}
+
+// the projects that devsecrets has secrets for.  see the projects package
+func GetProjectsFileName() string {
+	return filepath.Join(GetConfigDir(), "projects.json")
+}
+
+// where versions before projects kept the secrets of every project.  update copies them from there
+func GetLegacySecretFileName() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(homeDir, ".devsecrets.env")
+}
+
+// adds the project of the manifest that was loaded with LoadSecretFile() to the projects that "env --all" loads
+func RegisterProject() error {
+	manifest, err := filepath.Abs(Value("input-file"))
+	if err != nil {
+		return err
+	}
+	unlock, err := wrappers.LockFile(GetConfigLockFileName())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	registry, err := projects.Load(GetProjectsFileName())
+	if err != nil {
+		return err
+	}
+	if registry.Register(ProjectName(), projects.Project{Manifest: manifest, Store: LocalSecrets.Options.Store}) {
+		return registry.Save()
+	}
+	return nil
+}
+
+/*
+for the commands that can run without --input-file: sets it to the manifest of the project of the current directory.
+returns false if --input-file is already set or the current directory isn't in a registered project
+*/
+func UseCurrentProject() bool {
+	if Value("input-file") != "" {
+		return false
+	}
+	registry, err := projects.Load(GetProjectsFileName())
+	if err != nil {
+		return false
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	name := registry.ForDir(cwd)
+	if name == "" {
+		return false
+	}
+	FindSettingByName("input-file").SetValue(registry.Projects[name].Manifest)
+	return true
+}
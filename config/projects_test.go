@@ -0,0 +1,33 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProjectName(t *testing.T) {
+	defer func() { LocalSecrets = DevSecrets{} }()
+	app := DefaultProjectName("/workspaces/app/devsecrets.json")
+	if !strings.HasPrefix(app, "app-") || len(app) != len("app-")+8 {
+		t.Errorf("DefaultProjectName() = %s, want app-<8 hex digits>", app)
+	}
+	if other := DefaultProjectName("/home/vscode/src/app/devsecrets.json"); other == app {
+		t.Errorf("DefaultProjectName() of two manifests in directories with the same name = %s", other)
+	}
+	if got := DefaultProjectName("/workspaces/my app/.devcontainer/devsecrets.json"); !strings.HasPrefix(got, "devcontainer-") {
+		t.Errorf("DefaultProjectName() = %s, want it to start with devcontainer-", got)
+	}
+
+	for _, name := range []string{"contoso-app", "app_2.0"} {
+		LocalSecrets.Project = name
+		if err := CheckProject(); err != nil || ProjectName() != name {
+			t.Errorf("ProjectName() = %s, %v, want %s", ProjectName(), err, name)
+		}
+	}
+	for _, name := range []string{"../app", "my app", ".hidden", "-app"} {
+		LocalSecrets.Project = name
+		if err := CheckProject(); err == nil {
+			t.Errorf("CheckProject() accepted \"%s\"", name)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+/*
+a Provider is a shell script that returns the values of several secrets at once, e.g. the appId, password and tenant
+of a service principal from one az call.  the script returns a JSON object (see wrappers.ParseScriptJSON()) and
+Outputs maps its keys to the environment variables of secrets in the "secrets" section:
+
+	"providers": [
+	    {
+	        "name": "azure-sp",
+	        "shellscript": "./.devcontainer/get-azure-sp.sh",
+	        "outputs": { "appId": "AZURE_CLIENT_ID", "password": "AZURE_CLIENT_SECRET", "tenant": "AZURE_TENANT_ID" }
+	    }
+	]
+
+update runs a provider at most once, no matter how many of its secrets need a value.
+*/
+type Provider struct {
+	Name        string            `json:"name"`
+	ShellScript string            `json:"shellscript"`
+	Args        []string          `json:"args,omitempty"`        // passed to the shell script
+	Timeout     string            `json:"timeout,omitempty"`     // how long the shell script can run, e.g. "30s".  "" is forever
+	Cwd         string            `json:"cwd,omitempty"`         // where the shell script runs, relative to devsecrets.json
+	Outputs     map[string]string `json:"outputs"`               // the key in the JSON the script returns -> the environment variable
+	DependsOn   []string          `json:"dependsOn,omitempty"`   // secrets whose values the shell script gets in its environment
+	Interactive *bool             `json:"interactive,omitempty"` // nil means the shell script is interactive
+}
+
+// returns true if the shell script of the provider can read from the terminal.  see Secret.IsInteractive()
+func (p Provider) IsInteractive() bool {
+	return p.Interactive == nil || *p.Interactive
+}
+
+// how long the shell script of the provider can run before it is killed.  0 means it can run forever
+func (p Provider) ScriptTimeout() time.Duration {
+	return scriptTimeout(p.Timeout)
+}
+
+// the directory the shell script of the provider runs in
+func (p Provider) ScriptDir() string {
+	return scriptDir(p.Cwd)
+}
+
+// returns the keys of Outputs in alphabetical order
+func (p Provider) OutputKeys() (keys []string) {
+	for key := range p.Outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+/*
+returns the provider that sets the environment variable and the key of the value in what it returns, or nil if the
+secret isn't set by a provider
+*/
+func FindProvider(environmentVariable string) (provider *Provider, key string) {
+	for i := range LocalSecrets.Providers {
+		for _, key := range LocalSecrets.Providers[i].OutputKeys() {
+			if LocalSecrets.Providers[i].Outputs[key] == environmentVariable {
+				return &LocalSecrets.Providers[i], key
+			}
+		}
+	}
+	return nil, ""
+}
+
+/*
+makes sure that the providers make sense: each one has a unique name and a script, and every output goes to a secret
+in the manifest that doesn't get its value from anywhere else
+*/
+func CheckProviders() error {
+	names := map[string]bool{}
+	setBy := map[string]string{} // environment variable -> the provider that sets it
+	for _, p := range LocalSecrets.Providers {
+		if p.Name == "" || names[p.Name] {
+			return fmt.Errorf("every provider needs a unique name.  \"%s\" is empty or used more than once", p.Name)
+		}
+		names[p.Name] = true
+		if p.ShellScript == "" || len(p.Outputs) == 0 {
+			return fmt.Errorf("provider %s: shellscript and outputs must be set", p.Name)
+		}
+		if p.Timeout != "" {
+			if d, err := time.ParseDuration(p.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("provider %s: timeout is not a positive duration (e.g. \"30s\")", p.Name)
+			}
+		}
+		for _, key := range p.OutputKeys() {
+			name := p.Outputs[key]
+			secret := FindSecret(name)
+			switch {
+			case secret == nil:
+				return fmt.Errorf("provider %s: %s is not in the secrets", p.Name, name)
+			case secret.ShellScript != "":
+				return fmt.Errorf("provider %s: %s already has a shellscript", p.Name, name)
+			case setBy[name] != "":
+				return fmt.Errorf("provider %s: %s is already set by provider %s", p.Name, name, setBy[name])
+			}
+			setBy[name] = p.Name
+		}
+	}
+	return nil
+}
+
+// returns the shell scripts of the secrets and the providers, each one once
+func ScriptFiles() (fileNames []string) {
+	seen := map[string]bool{}
+	add := func(fileName string) {
+		if fileName != "" && !seen[fileName] {
+			seen[fileName] = true
+			fileNames = append(fileNames, fileName)
+		}
+	}
+	for _, s := range LocalSecrets.Secrets {
+		add(s.ShellScript)
+	}
+	for _, p := range LocalSecrets.Providers {
+		add(p.ShellScript)
+	}
+	return
+}
@@ -0,0 +1,41 @@
+package config
+
+import "testing"
+
+func TestCheckProviders(t *testing.T) {
+	defer func() { LocalSecrets = DevSecrets{} }()
+	LocalSecrets.Secrets = []Secret{
+		{EnvironmentVariable: "AZURE_CLIENT_ID"},
+		{EnvironmentVariable: "AZURE_CLIENT_SECRET"},
+		{EnvironmentVariable: "AZURE_SUB_ID", ShellScript: "./getAzureSub.sh"},
+	}
+	sp := Provider{Name: "azure-sp", ShellScript: "./sp.sh", Outputs: map[string]string{"appId": "AZURE_CLIENT_ID", "password": "AZURE_CLIENT_SECRET"}}
+
+	LocalSecrets.Providers = []Provider{sp}
+	if err := CheckProviders(); err != nil {
+		t.Errorf("CheckProviders() error = %v", err)
+	}
+	if p, key := FindProvider("AZURE_CLIENT_SECRET"); p == nil || p.Name != "azure-sp" || key != "password" {
+		t.Errorf("FindProvider() = %v, %s", p, key)
+	}
+	if p, _ := FindProvider("AZURE_SUB_ID"); p != nil {
+		t.Errorf("FindProvider() of a secret without a provider = %v", p)
+	}
+
+	bad := []Provider{
+		{Name: "no-script", Outputs: sp.Outputs},
+		{Name: "unknown", ShellScript: "./sp.sh", Outputs: map[string]string{"x": "NOT_A_SECRET"}},
+		{Name: "has-script", ShellScript: "./sp.sh", Outputs: map[string]string{"x": "AZURE_SUB_ID"}},
+		{Name: "timeout", ShellScript: "./sp.sh", Timeout: "soon", Outputs: map[string]string{"x": "AZURE_CLIENT_ID"}},
+	}
+	for _, p := range bad {
+		LocalSecrets.Providers = []Provider{p}
+		if err := CheckProviders(); err == nil {
+			t.Errorf("CheckProviders() of %s didn't return an error", p.Name)
+		}
+	}
+	LocalSecrets.Providers = []Provider{sp, {Name: "twice", ShellScript: "./sp.sh", Outputs: map[string]string{"x": "AZURE_CLIENT_ID"}}}
+	if err := CheckProviders(); err == nil {
+		t.Error("CheckProviders() of two providers for the same secret didn't return an error")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"strings"
 
@@ -431,16 +432,67 @@ func (s Setting) FillChar(row int, col int) string {
 	}
 }
 
+// where the value of a secret came from.  recorded in the env file and reported by "devsecrets verify"
+const (
+	SourcePrompt      = "prompt"
+	SourceShellScript = "shellscript"
+	SourceProvider    = "provider"
+	SourceEnvironment = "environment"
+	SourceGitHub      = "github"
+	SourceSkipped     = "skipped" // an optional secret that the user didn't enter a value for
+	SourceDotenv      = "dotenv"  // the --from-file input of update
+)
+
 type Secret struct {
-	EnvironmentVariable string `json:"environmentVariable"`
-	Description         string `json:"description"`
-	ShellScript         string `json:"shellscript"`
+	EnvironmentVariable string   `json:"environmentVariable"`
+	Description         string   `json:"description"`
+	ShellScript         string   `json:"shellscript"`
+	Args                []string `json:"args,omitempty"`        // passed to the shell script
+	Timeout             string   `json:"timeout,omitempty"`     // how long the shell script can run, e.g. "30s".  "" is forever
+	Cwd                 string   `json:"cwd,omitempty"`         // where the shell script runs, relative to devsecrets.json
+	Output              string   `json:"output,omitempty"`      // "json" if the shell script returns JSON.  see wrappers.ParseScriptJSON()
+	DependsOn           []string `json:"dependsOn,omitempty"`   // secrets whose values the shell script gets in its environment
+	Interactive         *bool    `json:"interactive,omitempty"` // nil means the shell script is interactive -- see IsInteractive()
+	Masked              *bool    `json:"masked,omitempty"`      // nil means masked -- see IsMasked()
+	Required            *bool    `json:"required,omitempty"`    // nil means required -- see IsRequired()
+	Default             string   `json:"default,omitempty"`     // the value used when the user just hits enter
+
+	// optional rules that a value has to pass.  see validate.go
+	Pattern   string   `json:"pattern,omitempty"` // a regular expression that has to match the whole value
+	MinLength int      `json:"minLength,omitempty"`
+	MaxLength int      `json:"maxLength,omitempty"`
+	Type      string   `json:"type,omitempty"` // one of SecretTypes
+	Enum      []string `json:"enum,omitempty"` // the only values that are allowed
+}
+
+// returns true if the secret has to have a value.  an optional secret can be skipped by hitting enter at the prompt
+func (s Secret) IsRequired() bool {
+	return s.Required == nil || *s.Required
+}
+
+/*
+returns true if the shell script of the secret can read from the terminal.  scripts are interactive unless
+"interactive" is false, and only scripts that aren't interactive run at the same time as other scripts
+*/
+func (s Secret) IsInteractive() bool {
+	return s.Interactive == nil || *s.Interactive
+}
+
+// returns true if what the user types for the secret shouldn't be echoed.  secrets are masked unless "masked" is false
+func (s Secret) IsMasked() bool {
+	return s.Masked == nil || *s.Masked
 }
+
 type DevSecrets struct {
+	Project string `json:"project"` // the project the secrets belong to.  "" names it after the manifest.  see projects.go
 	Options struct {
-		UseGitHubUserSecrets bool `json:"useGitHubUserSecrets"`
+		UseGitHubUserSecrets bool   `json:"useGitHubUserSecrets"`
+		Store                string `json:"store"`     // where the values are kept.  "" is the default env file
+		ShowStars            bool   `json:"showStars"` // show a * for each character typed for a masked secret
+		SkipFor              string `json:"skipFor"`   // how long "skip for now" lasts, e.g. "8h".  see SkipDuration()
 	} `json:"options"`
-	Secrets []Secret `json:"secrets"`
+	Secrets   []Secret   `json:"secrets"`
+	Providers []Provider `json:"providers"` // scripts that set several secrets at once.  see providers.go
 }
 
 var LocalSecrets DevSecrets
@@ -492,15 +544,146 @@ func LoadSecretFile()(err error) {
 		os.Exit(2)
 	}
 	err = json.Unmarshal(bytes, &LocalSecrets)
+	if err != nil {
+		return
+	}
+	if skipFor := LocalSecrets.Options.SkipFor; skipFor != "" {
+		if _, err = time.ParseDuration(skipFor); err != nil {
+			globals.EchoError("error in " + inputFile + " skipFor is not a duration (e.g. \"8h\"): " + err.Error() + "\n")
+			os.Exit(2)
+		}
+	}
+	if err = CheckNames(); err != nil {
+		globals.EchoError("error in " + inputFile + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+	for _, s := range LocalSecrets.Secrets {
+		if err = s.CheckScript(); err != nil {
+			globals.EchoError("error in " + inputFile + " " + err.Error() + "\n")
+			os.Exit(2)
+		}
+		if err = s.CheckRules(); err != nil {
+			globals.EchoError("error in " + inputFile + " " + err.Error() + "\n")
+			os.Exit(2)
+		}
+	}
+	if err = CheckProject(); err != nil {
+		globals.EchoError("error in " + inputFile + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+	if err = CheckProviders(); err != nil {
+		globals.EchoError("error in " + inputFile + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
+	if err = CheckDependencies(); err != nil {
+		globals.EchoError("error in " + inputFile + " " + err.Error() + "\n")
+		os.Exit(2)
+	}
 	return
 }
 
-func GetSecretFileName()(secretFileName string ) {
-	var secretEnvFile = ".devsecrets.env"
+// returns the secret in the manifest for the environment variable, or nil if there isn't one
+func FindSecret(environmentVariable string) *Secret {
+	for i := range LocalSecrets.Secrets {
+		if LocalSecrets.Secrets[i].EnvironmentVariable == environmentVariable {
+			return &LocalSecrets.Secrets[i]
+		}
+	}
+	return nil
+}
+
+// the env file of the project of the manifest that was loaded with LoadSecretFile()
+func GetSecretFileName() string {
+	return ProjectSecretFileName(ProjectName())
+}
+
+// how long update doesn't ask for a secret after the user picked "skip for now"
+func SkipDuration() time.Duration {
+	d, err := time.ParseDuration(LocalSecrets.Options.SkipFor)
+	if err != nil || d <= 0 { // LoadSecretFile() reports a skipFor that isn't a duration
+		return 24 * time.Hour
+	}
+	return d
+}
+
+// makes sure that the settings for the shell script of the secret make sense
+func (s Secret) CheckScript() error {
+	if s.Timeout != "" {
+		if d, err := time.ParseDuration(s.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("%s: timeout is not a positive duration (e.g. \"30s\")", s.EnvironmentVariable)
+		}
+	}
+	if s.Output != "" && s.Output != ScriptOutputJSON {
+		return fmt.Errorf("%s: output must be \"%s\" or left out, not \"%s\"", s.EnvironmentVariable, ScriptOutputJSON, s.Output)
+	}
+	return nil
+}
+
+// the "output" of a secret whose shell script returns JSON
+const ScriptOutputJSON = "json"
+
+// how long the shell script of the secret can run before it is killed.  0 means it can run forever
+func (s Secret) ScriptTimeout() time.Duration {
+	return scriptTimeout(s.Timeout)
+}
+
+func scriptTimeout(timeout string) time.Duration {
+	d, err := time.ParseDuration(timeout)
+	if err != nil { // LoadSecretFile() reports a timeout that isn't a duration
+		return 0
+	}
+	return d
+}
+
+// the directory the shell script of the secret runs in.  a relative cwd is relative to the directory of the input file
+func (s Secret) ScriptDir() string {
+	return scriptDir(s.Cwd)
+}
+
+func scriptDir(cwd string) string {
+	if cwd == "" || filepath.IsAbs(cwd) {
+		return cwd
+	}
+	return filepath.Join(filepath.Dir(Value("input-file")), cwd)
+}
+
+// remembers the secrets of the project that the user skipped or doesn't want to be asked for.  see the state package
+func GetStateFileName() string {
+	return ProjectStateFileName(ProjectName())
+}
+
+// the versions of the manifest and the shell scripts that the user trusts.  see the trust package
+func GetTrustFileName() string {
+	return filepath.Join(GetConfigDir(), "trust.json")
+}
+
+// the file that update and delete lock so that only one of them changes the secrets at a time
+func GetLockFileName() string {
+	return GetSecretFileName() + ".lock"
+}
+
+/*
+the file that is locked while projects.json or trust.json is read, changed and written.  every project shares them,
+so the lock of the project isn't enough -- two terminals updating different projects would each write back what they
+read, and one of them would lose its change
+*/
+func GetConfigLockFileName() string {
+	return filepath.Join(GetConfigDir(), "config.lock")
+}
+
+/*
+returns $HOME/.config/devsecrets, where devsecrets keeps files that belong to the user rather than to a project (keys,
+state...).  the directory is created if it doesn't exist
+*/
+func GetConfigDir() (configDir string) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		panic(err)
 	}
-	secretFileName = filepath.Join(homeDir, secretEnvFile)
+	configDir = filepath.Join(homeDir, ".config", "devsecrets")
+	err = os.MkdirAll(configDir, 0700)
+	if err != nil {
+		panic(err)
+	}
 	return
-}
\ No newline at end of file
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"devsecrets/shells"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// the values "type" can have in devsecrets.json
+var SecretTypes = []string{"string", "int", "bool", "url", "uuid", "email", "base64", "json", "pem"}
+
+var regExUuid = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+/*
+makes sure that every environmentVariable (and every environment variable a provider sets) is a valid name.  the
+names are written into the statements that "devsecrets env" prints for the shell to evaluate, so a name like
+"X=1; touch /tmp/pwned; Y" would run code in every terminal -- without any script running for the trust check to stop
+*/
+func CheckNames() error {
+	for _, s := range LocalSecrets.Secrets {
+		if !shells.IsVariableName(s.EnvironmentVariable) {
+			return fmt.Errorf("environmentVariable \"%s\" can only have letters, digits and '_', and can't start with a digit",
+				s.EnvironmentVariable)
+		}
+	}
+	for _, p := range LocalSecrets.Providers {
+		for _, key := range p.OutputKeys() {
+			if name := p.Outputs[key]; !shells.IsVariableName(name) {
+				return fmt.Errorf("provider %s: \"%s\" in outputs can only have letters, digits and '_', and can't start with a digit",
+					p.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// returns true if the secret has any rules to check a value against
+func (s Secret) HasRules() bool {
+	return s.Pattern != "" || s.MinLength != 0 || s.MaxLength != 0 || s.Type != "" || len(s.Enum) != 0
+}
+
+/*
+makes sure that the rules in devsecrets.json make sense: the pattern has to compile, the type has to be one we know
+and the lengths can't contradict each other.  this is checked when the file is loaded so that a typo in the manifest
+is reported once instead of as every value being invalid
+*/
+func (s Secret) CheckRules() error {
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("%s: pattern is not a valid regular expression: %w", s.EnvironmentVariable, err)
+		}
+	}
+	if s.Type != "" && !isSecretType(s.Type) {
+		return fmt.Errorf("%s: unknown type \"%s\".  valid types are: %s", s.EnvironmentVariable, s.Type,
+			strings.Join(SecretTypes, ", "))
+	}
+	if s.MinLength < 0 || s.MaxLength < 0 || (s.MaxLength != 0 && s.MinLength > s.MaxLength) {
+		return fmt.Errorf("%s: minLength and maxLength must be positive and minLength can't be more than maxLength",
+			s.EnvironmentVariable)
+	}
+	return nil
+}
+
+func isSecretType(t string) bool {
+	for _, st := range SecretTypes {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+validates a value for the secret using the same machinery as the cli settings.  returns Validated if the value passes
+every rule, Invalid (with the reason) if it doesn't, and Unknown if the secret doesn't have any rules to check
+*/
+func (s Secret) Validate(value string) (validity Validation, reason string) {
+	if !s.HasRules() {
+		return Unknown, ""
+	}
+	setting := New(s.EnvironmentVariable, value, "", s.checkValue)
+	if !setting.Validate() && value == "" {
+		// Setting.Validate() rejects an empty value before the rules are checked
+		return Invalid, "cannot be empty"
+	}
+	return setting.Validation(), setting.Description
+}
+
+// the checkValidity function for a Setting that holds the value of the secret
+func (s Secret) checkValue(setting *Setting) bool {
+	if reason := s.brokenRule(setting.ValueS()); reason != "" {
+		setting.SetValidated(Invalid)
+		setting.SetDescription(reason)
+		return false
+	}
+	setting.SetValidated(Validated)
+	return true
+}
+
+// returns why the value breaks one of the rules of the secret, or "" if it doesn't
+func (s Secret) brokenRule(value string) string {
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != 0 && length < s.MinLength {
+		return fmt.Sprint("must be at least ", s.MinLength, " characters long")
+	}
+	if s.MaxLength != 0 && length > s.MaxLength {
+		return fmt.Sprint("must be at most ", s.MaxLength, " characters long")
+	}
+	if len(s.Enum) != 0 {
+		found := false
+		for _, e := range s.Enum {
+			found = found || e == value
+		}
+		if !found {
+			return "must be one of: " + strings.Join(s.Enum, ", ")
+		}
+	}
+	if s.Pattern != "" {
+		// the pattern has to match the whole value, not just a part of it
+		if !regexp.MustCompile(`^(?:` + s.Pattern + `)$`).MatchString(value) {
+			return "must match the pattern " + s.Pattern
+		}
+	}
+	if s.Type != "" && !isOfType(s.Type, value) {
+		return "is not a valid " + s.Type
+	}
+	return ""
+}
+
+func isOfType(t string, value string) bool {
+	var err error
+	switch t {
+	case "int":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "url":
+		var u *url.URL
+		u, err = url.Parse(value)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	case "uuid":
+		return regExUuid.MatchString(value)
+	case "email":
+		var address *mail.Address
+		address, err = mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "base64":
+		if _, err = base64.StdEncoding.DecodeString(value); err != nil {
+			_, err = base64.URLEncoding.DecodeString(value)
+		}
+	case "json":
+		return json.Valid([]byte(value))
+	case "pem":
+		block, rest := pem.Decode([]byte(value))
+		return block != nil && strings.TrimSpace(string(rest)) == ""
+	}
+	return err == nil
+}
@@ -0,0 +1,98 @@
+package envfile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// reads a dotenv file, e.g. the --from-file input of "devsecrets update"
+func ReadDotenv(fileName string) (map[string]string, error) {
+	bytes, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := ParseDotenv(string(bytes))
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", fileName, err)
+	}
+	return vars, nil
+}
+
+/*
+parses the dotenv format that docker, python-dotenv and "devsecrets env --shell dotenv" use: NAME=value lines, with an
+optional "export " in front.  a '...' value is literal, a "..." value understands the \n, \r, \t, \\, \" and \$
+escapes, and both can span lines.  an unquoted value ends at " #".  unlike the env file, nothing here is ever
+evaluated by a shell, so a line that isn't NAME=value is an error instead of something to keep.
+*/
+func ParseDotenv(contents string) (vars map[string]string, err error) {
+	vars = map[string]string{}
+	lines := strings.Split(strings.ReplaceAll(contents, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, rest, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		name = strings.TrimSpace(name)
+		if !found || !validName.MatchString(name) {
+			return nil, fmt.Errorf("line %d: expected NAME=value", lineNumber)
+		}
+
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" || (rest[0] != '\'' && rest[0] != '"') {
+			if j := strings.Index(rest, " #"); j != -1 {
+				rest = rest[:j]
+			}
+			vars[name] = strings.TrimSpace(rest)
+			continue
+		}
+
+		value, after, complete := dotenvQuoted(rest)
+		for !complete {
+			if i+1 == len(lines) {
+				return nil, fmt.Errorf("line %d: the quote around the value of %s is never closed", lineNumber, name)
+			}
+			i++
+			rest += "\n" + lines[i]
+			value, after, complete = dotenvQuoted(rest)
+		}
+		if after = strings.TrimSpace(after); after != "" && !strings.HasPrefix(after, "#") {
+			return nil, fmt.Errorf("line %d: unexpected \"%s\" after the value of %s", lineNumber, after, name)
+		}
+		vars[name] = value
+	}
+	return
+}
+
+// s starts with the quote.  returns the value inside the quotes and whatever comes after the closing quote
+func dotenvQuoted(s string) (value string, after string, complete bool) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			return b.String(), s[i+1:], true
+		case c == '\\' && quote == '"' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '$':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
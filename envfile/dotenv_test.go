@@ -0,0 +1,59 @@
+package envfile
+
+import (
+	"devsecrets/shells"
+	"testing"
+)
+
+// whatever "devsecrets env --shell dotenv" prints has to come back unchanged
+func TestParseDotenvRoundTrip(t *testing.T) {
+	vars := []shells.Variable{}
+	for _, e := range testEntries() {
+		vars = append(vars, shells.Variable{Name: e.Name, Value: e.Value})
+	}
+	out, err := shells.Format("dotenv", vars)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	got, err := ParseDotenv(out)
+	if err != nil {
+		t.Fatalf("ParseDotenv() error = %v", err)
+	}
+	for _, v := range vars {
+		if got[v.Name] != v.Value {
+			t.Errorf("dotenv round trip of %q = %q", v.Value, got[v.Name])
+		}
+	}
+}
+
+func TestParseDotenv(t *testing.T) {
+	contents := "# written by hand\n" +
+		"PLAIN=a b # a comment\n" +
+		"export SINGLE='$HOME \\n'\n" +
+		"DOUBLE=\"line one\nline two\" # continues\n" +
+		"EMPTY=\n"
+	want := map[string]string{
+		"PLAIN":  "a b",
+		"SINGLE": `$HOME \n`,
+		"DOUBLE": "line one\nline two",
+		"EMPTY":  "",
+	}
+	got, err := ParseDotenv(contents)
+	if err != nil {
+		t.Fatalf("ParseDotenv() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("ParseDotenv() = %v, want %v", got, want)
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("ParseDotenv() %s = %q, want %q", name, got[name], value)
+		}
+	}
+
+	for _, bad := range []string{"not a variable", "1NAME=x", "OPEN='never closed\n", `EXTRA="x" y`} {
+		if _, err = ParseDotenv(bad); err == nil {
+			t.Errorf("ParseDotenv(%q) didn't return an error", bad)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package globals
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strings"
@@ -33,6 +34,10 @@ const ClearLineRight = "\x1b[0K"
 
 var Verbose = false // verbose mode set in infra.go
 
+// exit codes that scripts (e.g. postCreateCommand or CI) can check for
+const ExitMissingSecrets = 3
+const ExitInvalidSecrets = 4
+
 type EchoLevel int
 
 const (
@@ -61,7 +66,24 @@ an internal function that just echos a plain string to the console
 we need this so we can filter out PATs.
 */
 func internalEcho(s string) {
-	fmt.Print(HidePat((s)))
+	fmt.Fprint(Console(), HidePat((s)))
+}
+
+/*
+set by commands whose stdout is data that another program reads (e.g. "eval $(devsecrets env)"). when it is set,
+the Echo* functions write to stderr instead of stdout
+*/
+var EchoToStderr = false
+
+// the cobra annotation a command sets to have EchoToStderr turned on before it runs
+const StdoutIsData = "stdout-is-data"
+
+// returns where the Echo* functions write to
+func Console() io.Writer {
+	if EchoToStderr {
+		return os.Stderr
+	}
+	return os.Stdout
 }
 
 // prints the string in red to stderr and then resets the color to normal
@@ -73,21 +95,21 @@ func EchoError(a ...any) {
 
 // prints the string in yellow and then resets the color to normal
 func EchoWarning(a ...any) {
-	fmt.Print(ColorYellow)
+	fmt.Fprint(Console(), ColorYellow)
 	internalEcho(fmt.Sprint(a...))
-	fmt.Print(ColorReset)
+	fmt.Fprint(Console(), ColorReset)
 }
 
 // prints the string in green and then resets the color to normal
 func EchoInfo(a ...any) {
-	fmt.Print(ColorGreen)
+	fmt.Fprint(Console(), ColorGreen)
 	internalEcho(fmt.Sprint(a...))
-	fmt.Print(ColorReset)
+	fmt.Fprint(Console(), ColorReset)
 }
 
 // print in default color
 func Echo(a ...any) {
-	fmt.Print(ColorReset)
+	fmt.Fprint(Console(), ColorReset)
 	internalEcho(fmt.Sprint(a...))
 }
 
@@ -174,9 +196,8 @@ func PanicOnError(err error) {
 */
 func EnterBoolean(prompt string, def bool) (val bool) {
 	EchoWarning(prompt)
-	var input string
-	fmt.Scanln(&input)
-	input = strings.ToLower(input)
+	input, _ := readLine(stdin)
+	input = strings.ToLower(strings.TrimSpace(input))
 	if input == "y" || input == "true" || input == "t" {
 		val = true
 	} else if input == "" {
@@ -187,22 +208,55 @@ func EnterBoolean(prompt string, def bool) (val bool) {
 	return
 }
 
+/*
+every prompt reads from this one reader.  a reader per prompt would buffer (and then throw away) the lines after the
+first one when the answers are piped in
+*/
+var stdin = bufio.NewReader(os.Stdin)
+
+// returns the next line without the line ending.  the last line doesn't need a line ending
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
 /*
 prompt the user for a string and returns it
-Note:  this can't use fmt.Scanln() like EnterBoolean because fmt.Scanln() splits on spaces so no words with spaces
+Note:  this reads the whole line because fmt.Scanln() splits on spaces so no words with spaces
 can be entered, and it is common for the AAD Group Name to have a space in it.  if there is an error, the caller
 will ignore it and just print the prompt again
 */
 func EnterString(prompt string) (val string) {
 	EchoWarning(prompt)
-	scanner := bufio.NewScanner(os.Stdin)
-	if scanner.Scan() {
-		val = scanner.Text()
-	}
+	val, _ = readLine(stdin)
+	return
+}
 
+/*
+prompt the user for a secret without echoing what they type, so that it doesn't end up in the scrollback or in a
+screen share.  if stars is set, a * is shown for each character.  when stdin isn't a terminal (e.g. the value is piped
+in) there is nothing to hide and this is the same as EnterString()
+*/
+func EnterSecret(prompt string, stars bool) (val string) {
+	if !StdinIsTerminal() {
+		return EnterString(prompt)
+	}
+	EchoWarning(prompt)
+	val, err := readMasked(int(os.Stdin.Fd()), stdin, Console(), stars)
+	if err != nil && err != io.EOF {
+		EchoError("unable to turn off echo: ", err.Error(), "\n")
+	}
 	return
 }
 
+// returns true if the user can be prompted -- stdin is a terminal and not a pipe or a file
+func StdinIsTerminal() bool {
+	return isTerminal(int(os.Stdin.Fd()))
+}
+
 var RegExGitlabAccount = regexp.MustCompile(`glpat-[0-9a-zA-Z\\-]{20}`)
 var RegExGitHubAccount = regexp.MustCompile(`^(ghp_[a-zA-Z0-9]{36}|gho_[a-zA-Z0-9]{36}|github_pat_[a-zA-Z0-9]{22}_[a-zA-Z0-9]{59}|v[0-9]\\.[0-9a-f]{40})$`)
 
@@ -0,0 +1,8 @@
+//go:build linux
+
+package globals
+
+import "golang.org/x/sys/unix"
+
+const ioctlGetTermios = unix.TCGETS
+const ioctlSetTermios = unix.TCSETS
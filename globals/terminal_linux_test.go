@@ -0,0 +1,107 @@
+package globals
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// opens a new pty and returns both ends.  the test types into the master, and readMasked() reads from the slave
+func openPty(t *testing.T) (master *os.File, slave *os.File) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skip("no pty available: ", err)
+	}
+	fd := int(master.Fd())
+	if err = unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		t.Fatalf("unlockpt error = %v", err)
+	}
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		t.Fatalf("ptsname error = %v", err)
+	}
+	slave, err = os.OpenFile(fmt.Sprint("/dev/pts/", n), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Fatalf("open slave error = %v", err)
+	}
+	t.Cleanup(func() { slave.Close(); master.Close() })
+	return
+}
+
+func echoIsOn(t *testing.T, f *os.File) bool {
+	termios, err := unix.IoctlGetTermios(int(f.Fd()), ioctlGetTermios)
+	if err != nil {
+		t.Fatalf("IoctlGetTermios error = %v", err)
+	}
+	return termios.Lflag&unix.ECHO != 0
+}
+
+/*
+types a secret into the pty once echo is off and checks that it comes back, that the terminal never showed it
+and that echo is back on afterwards
+*/
+func TestReadMasked(t *testing.T) {
+	tests := []struct {
+		name   string
+		stars  bool
+		typed  string
+		want   string
+		screen string // what the terminal shows
+	}{
+		{"no echo", false, "glpat-s3cret value\n", "glpat-s3cret value", "\r\n"},
+		{"stars", true, "s3cx\x7fret\r", "s3cret", "****\b \b***\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master, slave := openPty(t)
+			if !isTerminal(int(slave.Fd())) {
+				t.Fatal("isTerminal() = false for a pty")
+			}
+			type result struct {
+				value string
+				err   error
+			}
+			done := make(chan result)
+			go func() {
+				value, err := readMasked(int(slave.Fd()), bufio.NewReader(slave), slave, tt.stars)
+				done <- result{value, err}
+			}()
+
+			// wait for readMasked() to turn echo off before typing, otherwise the terminal echoes it
+			for start := time.Now(); echoIsOn(t, slave); time.Sleep(time.Millisecond) {
+				if time.Since(start) > 5*time.Second {
+					t.Fatal("readMasked() never turned off echo")
+				}
+			}
+			if _, err := master.Write([]byte(tt.typed)); err != nil {
+				t.Fatalf("write to pty error = %v", err)
+			}
+
+			var r result
+			select {
+			case r = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("readMasked() didn't return")
+			}
+			if r.err != nil || r.value != tt.want {
+				t.Errorf("readMasked() = %q, %v, want %q", r.value, r.err, tt.want)
+			}
+			if !echoIsOn(t, slave) {
+				t.Error("readMasked() left echo off")
+			}
+
+			// everything written to the terminal is readable from the master once the slave is closed
+			slave.Close()
+			screen, _ := io.ReadAll(master)
+			if !bytes.Equal(screen, []byte(tt.screen)) {
+				t.Errorf("terminal showed %q, want %q", screen, tt.screen)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package globals
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// echo can only be turned off on unix, everywhere else input is read as plain text
+func isTerminal(fd int) bool {
+	return false
+}
+
+func readMasked(fd int, reader *bufio.Reader, out io.Writer, stars bool) (string, error) {
+	return "", errors.New("masked input is not supported on this platform")
+}
@@ -0,0 +1,103 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package globals
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// returns true if fd is a terminal (and not a pipe or a file)
+func isTerminal(fd int) bool {
+	_, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	return err == nil
+}
+
+/*
+reads a line from the terminal fd with echo turned off.  if stars is set, the terminal is also taken out of line mode
+so that a * can be written to out for each character typed (and erased again for a backspace).  the terminal is put
+back the way it was before returning -- and if the user hits Ctrl-C, before exiting, so that the shell isn't left
+without echo.
+*/
+func readMasked(fd int, reader *bufio.Reader, out io.Writer, stars bool) (string, error) {
+	old, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return "", err
+	}
+	masked := *old
+	masked.Lflag &^= unix.ECHO
+	if stars {
+		masked.Lflag &^= unix.ICANON
+		masked.Cc[unix.VMIN] = 1
+		masked.Cc[unix.VTIME] = 0
+	}
+	if err = unix.IoctlSetTermios(fd, ioctlSetTermios, &masked); err != nil {
+		return "", err
+	}
+
+	restore := func() { unix.IoctlSetTermios(fd, ioctlSetTermios, old) }
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-interrupted:
+			restore()
+			fmt.Fprintln(out)
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+	defer func() {
+		signal.Stop(interrupted)
+		close(done)
+		restore()
+		// the Enter the user typed wasn't echoed either
+		fmt.Fprintln(out)
+	}()
+
+	if !stars {
+		return readLine(reader)
+	}
+	return readWithStars(reader, out)
+}
+
+// reads one character at a time, writing a * for each of them
+func readWithStars(reader *bufio.Reader, out io.Writer) (string, error) {
+	value := []rune{}
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return string(value), err
+		}
+		switch r {
+		case '\n', '\r':
+			return string(value), nil
+		case 0x04: // Ctrl-D
+			if len(value) == 0 {
+				return "", io.EOF
+			}
+		case 0x7f, '\b':
+			if len(value) > 0 {
+				value = value[:len(value)-1]
+				fmt.Fprint(out, "\b \b")
+			}
+		case 0x15: // Ctrl-U erases the line
+			for range value {
+				fmt.Fprint(out, "\b \b")
+			}
+			value = value[:0]
+		default:
+			if r >= 0x20 || r == '\t' {
+				value = append(value, r)
+				fmt.Fprint(out, "*")
+			}
+		}
+	}
+}
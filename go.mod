@@ -7,7 +7,8 @@ require github.com/spf13/cobra v1.6.1 // direct
 require (
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.14.0
-
+	golang.org/x/sys v0.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -22,9 +23,7 @@ require (
 	github.com/spf13/cast v1.5.0 // indirect
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/subosito/gotenv v1.4.1 // indirect
-	golang.org/x/sys v0.3.0 // indirect
 	golang.org/x/text v0.5.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,99 @@
+/*
+remembers the projects that devsecrets keeps secrets for, so that "devsecrets env --all" can load the secrets of all
+of them and "devsecrets env" can find the project of the current directory without --input-file.  the projects are
+kept in $HOME/.config/devsecrets/projects.json:
+
+	{
+	    "projects": {
+	        "app-3f9a1c2e": { "manifest": "/workspaces/app/devsecrets.json", "store": "file" }
+	    }
+	}
+
+the names are the ones config.ProjectName() returns.
+*/
+package projects
+
+import (
+	"devsecrets/wrappers"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type Project struct {
+	Manifest string `json:"manifest"` // the absolute path of the devsecrets.json
+	Store    string `json:"store"`    // "options.store" in the manifest
+}
+
+type Registry struct {
+	FileName string             `json:"-"`
+	Projects map[string]Project `json:"projects"` // by the name of the project
+}
+
+// reads the registry.  a file that doesn't exist yet has no projects
+func Load(fileName string) (*Registry, error) {
+	r := &Registry{FileName: fileName, Projects: map[string]Project{}}
+	bytes, err := os.ReadFile(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(bytes, r); err != nil {
+		return nil, err
+	}
+	if r.Projects == nil {
+		r.Projects = map[string]Project{}
+	}
+	return r, nil
+}
+
+func (r *Registry) Save() error {
+	bytes, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return err
+	}
+	return wrappers.WriteFileAtomic(r.FileName, append(bytes, '\n'), 0600)
+}
+
+// adds the project or replaces it.  changed is false if it was already registered just like this
+func (r *Registry) Register(name string, project Project) (changed bool) {
+	if r.Projects[name] == project {
+		return false
+	}
+	r.Projects[name] = project
+	return true
+}
+
+// returns the names of the projects in alphabetical order
+func (r *Registry) Names() (names []string) {
+	for name := range r.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+/*
+returns the name of the project whose manifest is in dir, or in the closest directory above it.  "" if dir isn't in a
+project
+*/
+func (r *Registry) ForDir(dir string) (found string) {
+	longest := -1
+	for _, name := range r.Names() {
+		manifestDir := filepath.Dir(r.Projects[name].Manifest)
+		rel, err := filepath.Rel(manifestDir, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(manifestDir) > longest {
+			found, longest = name, len(manifestDir)
+		}
+	}
+	return
+}
@@ -0,0 +1,43 @@
+package projects
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r, err := Load(filepath.Join(t.TempDir(), "projects.json"))
+	if err != nil {
+		t.Fatalf("Load() of a new file error = %v", err)
+	}
+	app := Project{Manifest: "/workspaces/app/devsecrets.json", Store: "file"}
+	if !r.Register("app", app) || r.Register("app", app) {
+		t.Error("Register() changed = false for a new project or true for the same project")
+	}
+	r.Register("api", Project{Manifest: "/workspaces/app/services/api/devsecrets.json", Store: "encrypted"})
+	r.Register("application", Project{Manifest: "/workspaces/application/devsecrets.json"})
+	if err = r.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	r, err = Load(r.FileName)
+	if err != nil || len(r.Projects) != 3 || r.Projects["api"].Store != "encrypted" {
+		t.Fatalf("Load() = %+v, %v", r, err)
+	}
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"/workspaces/app", "app"},
+		{"/workspaces/app/src", "app"},
+		{"/workspaces/app/services/api/handlers", "api"},
+		{"/workspaces/application", "application"},
+		{"/workspaces", ""},
+		{"/workspaces/other", ""},
+	}
+	for _, tt := range tests {
+		if got := r.ForDir(tt.dir); got != tt.want {
+			t.Errorf("ForDir(%s) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package shells
+
+import (
+	"regexp"
+	"strings"
+)
+
+// the lines around what setup writes to a startup file.  every shell setup supports uses # for comments
+const (
+	BlockStart = "# >>> devsecrets >>>"
+	BlockEnd   = "# <<< devsecrets <<<"
+)
+
+// the lines that setup wrote before it used a block.  they are removed the first time the block is written
+var legacyHookLines = []*regexp.Regexp{
+	regexp.MustCompile(`^source \S*/\.devsecrets\.env$`),
+	regexp.MustCompile(`^'?\S*devsecrets'? update --verbose --all --input-file `),
+	regexp.MustCompile(`^eval "\$\('?\S*devsecrets'? env --shell `),
+}
+
+/*
+returns the contents of a startup file with hook between BlockStart and BlockEnd.  an existing block is replaced where
+it is, otherwise the block is added at the end.  nothing outside of the block is touched, except for the lines that
+older versions of setup wrote before there was a block
+*/
+func InstallBlock(contents string, hook string) string {
+	block := BlockStart + "\n" + hook
+	if !strings.HasSuffix(block, "\n") {
+		block += "\n"
+	}
+	block += BlockEnd + "\n"
+
+	lines := splitLines(contents)
+	start, end := findBlock(lines)
+	if start == -1 {
+		kept := []string{}
+		for _, line := range lines {
+			if !isLegacyHookLine(line) {
+				kept = append(kept, line)
+			}
+		}
+		out := strings.Join(kept, "\n")
+		if out != "" {
+			out += "\n"
+		}
+		return out + block
+	}
+	out := strings.Join(lines[:start], "\n")
+	if out != "" {
+		out += "\n"
+	}
+	out += block
+	if rest := lines[end+1:]; len(rest) != 0 {
+		out += strings.Join(rest, "\n") + "\n"
+	}
+	return out
+}
+
+// returns the contents of a startup file without the block.  removed is false if there wasn't one
+func RemoveBlock(contents string) (out string, removed bool) {
+	lines := splitLines(contents)
+	start, end := findBlock(lines)
+	if start == -1 {
+		return contents, false
+	}
+	lines = append(lines[:start], lines[end+1:]...)
+	if len(lines) == 0 {
+		return "", true
+	}
+	return strings.Join(lines, "\n") + "\n", true
+}
+
+// returns the index of the BlockStart and BlockEnd lines, or -1, -1 if there isn't a complete block
+func findBlock(lines []string) (start int, end int) {
+	for i, line := range lines {
+		if strings.TrimSpace(line) == BlockStart {
+			for j := i + 1; j < len(lines); j++ {
+				if strings.TrimSpace(lines[j]) == BlockEnd {
+					return i, j
+				}
+			}
+		}
+	}
+	return -1, -1
+}
+
+func isLegacyHookLine(line string) bool {
+	for _, re := range legacyHookLines {
+		if re.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,82 @@
+package shells
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallBlock(t *testing.T) {
+	hook := "eval \"$('/usr/bin/devsecrets' env --shell bash)\"\n"
+	block := BlockStart + "\n" + hook + BlockEnd + "\n"
+	tests := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"empty file", "", block},
+		{"append", "alias ll='ls -l'\n", "alias ll='ls -l'\n" + block},
+		{"no trailing newline", "alias ll='ls -l'", "alias ll='ls -l'\n" + block},
+		{"replace in place",
+			"export PATH=$PATH:/opt/devsecrets/bin\n" + BlockStart + "\nold hook\n" + BlockEnd + "\nalias ll='ls -l'\n",
+			"export PATH=$PATH:/opt/devsecrets/bin\n" + block + "alias ll='ls -l'\n"},
+		{"legacy lines",
+			"export PATH=$PATH:/opt/devsecrets/bin\nsource /home/vscode/.devsecrets.env\n" +
+				"/usr/bin/devsecrets update --verbose --all --input-file /workspaces/app/devsecrets.json\n" +
+				"source /home/vscode/.devsecrets.env\n",
+			"export PATH=$PATH:/opt/devsecrets/bin\n" + block},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := InstallBlock(tt.contents, hook); got != tt.want {
+				t.Errorf("InstallBlock() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveBlock(t *testing.T) {
+	contents := "alias ll='ls -l'\n" + BlockStart + "\nhook\n" + BlockEnd + "\nexport EDITOR=vim\n"
+	got, removed := RemoveBlock(contents)
+	if !removed || got != "alias ll='ls -l'\nexport EDITOR=vim\n" {
+		t.Errorf("RemoveBlock() = %q, %v", got, removed)
+	}
+	if got, removed = RemoveBlock(got); removed || got != "alias ll='ls -l'\nexport EDITOR=vim\n" {
+		t.Errorf("RemoveBlock() without a block = %q, %v", got, removed)
+	}
+}
+
+func TestWriteStartupFileThroughLink(t *testing.T) {
+	dir := t.TempDir()
+	dotfile := filepath.Join(dir, "dotfiles", "bashrc")
+	os.MkdirAll(filepath.Dir(dotfile), 0755)
+	os.WriteFile(dotfile, []byte("alias ll='ls -l'\n"), 0600)
+	link := filepath.Join(dir, ".bashrc")
+	os.Symlink(dotfile, link)
+
+	target, contents, err := ReadStartupFile(link)
+	if err != nil || target != dotfile || contents != "alias ll='ls -l'\n" {
+		t.Fatalf("ReadStartupFile() = %s, %q, %v", target, contents, err)
+	}
+	backup, err := WriteStartupFile(target, InstallBlock(contents, "hook\n"))
+	if err != nil {
+		t.Fatalf("WriteStartupFile() error = %v", err)
+	}
+	if old, _ := os.ReadFile(backup); string(old) != contents {
+		t.Errorf("backup = %q, want %q", old, contents)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("%s is no longer a link", link)
+	}
+	if info, _ := os.Stat(dotfile); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	_, contents, _ = ReadStartupFile(link)
+	if out, removed := RemoveBlock(contents); !removed || out != "alias ll='ls -l'\n" {
+		t.Errorf("RemoveBlock() = %q, %v", out, removed)
+	}
+	if _, contents, err = ReadStartupFile(filepath.Join(dir, ".zshrc")); err != nil || contents != "" {
+		t.Errorf("ReadStartupFile() of a missing file = %q, %v", contents, err)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+knows how each shell (and file format) that devsecrets supports sets environment variables.  "devsecrets env --shell"
+uses Format() to print the secrets in the dialect the shell understands, and setup uses Hook() to get the lines to put
+in the shell's startup file.
+
+every dialect quotes values so that the shell takes them literally -- a secret with a $, a ` or a quote in it must
+never be evaluated by the shell that loads it.
+*/
+package shells
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// one environment variable to print
+type Variable struct {
+	Name  string
+	Value string
+}
+
+const DefaultDialect = "bash"
+
+// the dialects that Format() understands
+var Dialects = []string{"bash", "zsh", "fish", "pwsh", "nu", "dotenv", "json"}
+
+// returns true if dialect is one of Dialects
+func IsDialect(dialect string) bool {
+	for _, d := range Dialects {
+		if d == dialect {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+the names that every dialect can use for an environment variable.  the name is written into the statements without
+quotes, so anything else (e.g. "X=1; touch /tmp/pwned; Y") could run code in the shell that evaluates them
+*/
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// returns true if name can be the name of an environment variable
+func IsVariableName(name string) bool {
+	return variableName.MatchString(name)
+}
+
+/*
+returns the statements (or file contents, for dotenv and json) that set the variables in the dialect.  a variable
+whose name isn't an environment variable name is an error, and nothing is returned
+*/
+func Format(dialect string, vars []Variable) (out string, err error) {
+	if !IsDialect(dialect) {
+		return "", fmt.Errorf("unknown shell \"%s\".  valid shells are: %s", dialect, strings.Join(Dialects, ", "))
+	}
+	for _, v := range vars {
+		if !IsVariableName(v.Name) {
+			return "", fmt.Errorf("\"%s\" is not a valid environment variable name", v.Name)
+		}
+	}
+	if dialect == "json" {
+		return formatJson(vars)
+	}
+	for _, v := range vars {
+		switch dialect {
+		case "bash", "zsh":
+			out += fmt.Sprint("export ", v.Name, "=", QuotePosix(v.Value), "\n")
+		case "fish":
+			out += fmt.Sprint("set -gx ", v.Name, " ", quoteFish(v.Value), "\n")
+		case "pwsh":
+			out += fmt.Sprint("$env:", v.Name, " = ", quotePwsh(v.Value), "\n")
+		case "nu":
+			out += fmt.Sprint("$env.", v.Name, " = ", quoteNu(v.Value), "\n")
+		case "dotenv":
+			out += fmt.Sprint(v.Name, "=", quoteDotenv(v.Value), "\n")
+		}
+	}
+	return
+}
+
+/*
+returns the lines to add to a shell's startup file.  the hook runs update for the manifest of every project and loads
+the secrets of all of them with "env --all".  the secrets are loaded before *and* after running update so that update
+can see the values of the environment variables.  nushell can't evaluate the statements that "env --shell nu" prints,
+so its hook loads the json dialect instead
+*/
+func Hook(dialect string, exe string, manifests []string) (string, error) {
+	var load string
+	var update func(manifest string) string
+	switch dialect {
+	case "bash", "zsh":
+		exe = QuotePosix(exe)
+		load = fmt.Sprint("eval \"$(", exe, " env --shell ", dialect, " --all)\"")
+		update = func(manifest string) string {
+			return fmt.Sprint(exe, " update --verbose --all --input-file ", QuotePosix(manifest))
+		}
+	case "fish":
+		exe = quoteFish(exe)
+		load = fmt.Sprint(exe, " env --shell fish --all | source")
+		update = func(manifest string) string {
+			return fmt.Sprint(exe, " update --verbose --all --input-file ", quoteFish(manifest))
+		}
+	case "pwsh":
+		exe = quotePwsh(exe)
+		load = fmt.Sprint("& ", exe, " env --shell pwsh --all | Out-String | Invoke-Expression")
+		update = func(manifest string) string {
+			return fmt.Sprint("& ", exe, " update --verbose --all --input-file ", quotePwsh(manifest))
+		}
+	case "nu":
+		exe = quoteNu(exe)
+		load = fmt.Sprint("^", exe, " env --shell json --all | from json | default {} | load-env")
+		// a failing external command is an error in nushell, and it would stop the rest of env.nu from running
+		update = func(manifest string) string {
+			return fmt.Sprint("try { ^", exe, " update --verbose --all --input-file ", quoteNu(manifest), " }")
+		}
+	default:
+		return "", fmt.Errorf("setup does not support %s", dialect)
+	}
+	hook := load + "\n"
+	for _, manifest := range manifests {
+		hook += update(manifest) + "\n"
+	}
+	return hook + load + "\n", nil
+}
+
+// wraps the value in single quotes.  a single quote in the value ends the quoted string, is escaped and starts a new one
+func QuotePosix(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// fish single quotes only understand two escapes: \\ and \'
+func quoteFish(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "'", `\'`)
+	return "'" + value + "'"
+}
+
+// PowerShell escapes a single quote by doubling it, and it treats the typographic single quotes the same way
+func quotePwsh(value string) string {
+	for _, q := range []string{"'", "‘", "’", "‚", "‛"} {
+		value = strings.ReplaceAll(value, q, q+q)
+	}
+	return "'" + value + "'"
+}
+
+// nushell double quoted strings use backslash escapes. anything that isn't printable is written as \u{hex}
+func quoteNu(value string) string {
+	var b strings.Builder
+	b.WriteString(`"`)
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteString(`\` + string(r))
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u{%x}`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
+
+/*
+dotenv files (docker, python-dotenv, the VS Code go extension...) understand double quoted values with \n, \\ and \"
+escapes.  a $ is escaped too so that tools that interpolate variables leave it alone
+*/
+func quoteDotenv(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`)
+	return `"` + r.Replace(value) + `"`
+}
+
+func formatJson(vars []Variable) (string, error) {
+	// encoding/json writes the keys of a map in sorted order
+	m := map[string]string{}
+	for _, v := range vars {
+		m[v.Name] = v.Value
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	err := encoder.Encode(m)
+	return buf.String(), err
+}
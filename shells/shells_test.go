@@ -0,0 +1,141 @@
+package shells
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// values that break a shell that doesn't quote correctly
+var hardValues = []string{
+	"plain",
+	"with spaces",
+	"it's",
+	`"double"`,
+	"$HOME and ${HOME}",
+	"`whoami` $(whoami)",
+	"# not a comment",
+	`back\slash`,
+	"line one\nline two",
+	"",
+}
+
+/*
+formats each value for bash, has bash evaluate it and print it back.  the value that comes back has to be exactly
+the value that went in
+*/
+func TestFormatBashRoundTrip(t *testing.T) {
+	for _, value := range hardValues {
+		out, err := Format("bash", []Variable{{"DEVSECRETS_TEST", value}})
+		if err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		got, err := exec.Command("bash", "-c", out+`printf %s "$DEVSECRETS_TEST"`).Output()
+		if err != nil {
+			t.Fatalf("bash error = %v running %s", err, out)
+		}
+		if string(got) != value {
+			t.Errorf("bash round trip of %q = %q", value, string(got))
+		}
+	}
+}
+
+func TestFormatJsonRoundTrip(t *testing.T) {
+	vars := []Variable{}
+	for i, value := range hardValues {
+		vars = append(vars, Variable{string(rune('A' + i)), value})
+	}
+	out, err := Format("json", vars)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	m := map[string]string{}
+	if err = json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	for _, v := range vars {
+		if m[v.Name] != v.Value {
+			t.Errorf("json round trip of %q = %q", v.Value, m[v.Name])
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		dialect string
+		value   string
+		want    string
+	}{
+		{"zsh", "it's", `export PAT='it'\''s'` + "\n"},
+		{"fish", `it's a \`, `set -gx PAT 'it\'s a \\'` + "\n"},
+		{"pwsh", "it's $HOME", `$env:PAT = 'it''s $HOME'` + "\n"},
+		{"nu", "say \"hi\"\n", `$env.PAT = "say \"hi\"\n"` + "\n"},
+		{"dotenv", "$HOME \"x\"\n", `PAT="\$HOME \"x\"\n"` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			got, err := Format(tt.dialect, []Variable{{"PAT", tt.value}})
+			if err != nil || got != tt.want {
+				t.Errorf("Format() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Format("cmd.exe", []Variable{{"PAT", "x"}}); err == nil {
+		t.Error("Format() accepted an unknown shell")
+	}
+	for _, dialect := range Dialects {
+		if _, err := Format(dialect, []Variable{{"X=1; touch /tmp/pwned; Y", "x"}}); err == nil {
+			t.Errorf("Format(%s) accepted a name that isn't a variable name", dialect)
+		}
+	}
+}
+
+func TestHook(t *testing.T) {
+	exe, manifests := "/usr/local/bin/devsecrets", []string{"/workspaces/it's/devsecrets.json", "/workspaces/api/devsecrets.json"}
+	tests := []struct {
+		dialect string
+		load    string
+		update  string
+	}{
+		{"bash", `eval "$('/usr/local/bin/devsecrets' env --shell bash --all)"`,
+			`'/usr/local/bin/devsecrets' update --verbose --all --input-file '/workspaces/it'\''s/devsecrets.json'`},
+		{"fish", `'/usr/local/bin/devsecrets' env --shell fish --all | source`,
+			`'/usr/local/bin/devsecrets' update --verbose --all --input-file '/workspaces/it\'s/devsecrets.json'`},
+		{"pwsh", `& '/usr/local/bin/devsecrets' env --shell pwsh --all | Out-String | Invoke-Expression`,
+			`& '/usr/local/bin/devsecrets' update --verbose --all --input-file '/workspaces/it''s/devsecrets.json'`},
+		{"nu", `^"/usr/local/bin/devsecrets" env --shell json --all | from json | default {} | load-env`,
+			`try { ^"/usr/local/bin/devsecrets" update --verbose --all --input-file "/workspaces/it's/devsecrets.json" }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			got, err := Hook(tt.dialect, exe, manifests)
+			lines := strings.Split(got, "\n")
+			if err != nil || len(lines) != 5 || lines[0] != tt.load || lines[1] != tt.update || lines[3] != tt.load ||
+				!strings.Contains(lines[2], "/workspaces/api/devsecrets.json") {
+				t.Errorf("Hook() = %v, %v, want %v and %v", got, err, tt.load, tt.update)
+			}
+		})
+	}
+
+	if _, err := Hook("tcsh", exe, manifests); err == nil {
+		t.Error("Hook() accepted an unknown shell")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	files := StartupFiles("/home/vscode")
+	got, err := Select(files, []string{"profile.d", "fish"})
+	if err != nil || len(got) != 2 || got[0].Shell != "fish" || got[1].FileName != ProfileD {
+		t.Errorf("Select() = %+v, %v", got, err)
+	}
+	if _, err = Select(files, []string{"bash", "tcsh"}); err == nil {
+		t.Error("Select() accepted an unknown shell")
+	}
+	for _, f := range files {
+		if _, err := Hook(f.Dialect, "devsecrets", []string{"devsecrets.json"}); err != nil {
+			t.Errorf("Hook() of the %s startup file error = %v", f.Shell, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package shells
+
+import (
+	"devsecrets/wrappers"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// the file setup writes with --shell profile.d.  every user's login shell sources it, e.g. in a container image build
+const ProfileD = "/etc/profile.d/devsecrets.sh"
+
+// a startup file that setup puts the hook for a shell into
+type StartupFile struct {
+	Shell    string // the name that setup --shell takes
+	Dialect  string // the dialect of the hook, see Hook()
+	FileName string
+	Program  string // setup installs the hook when this is on the PATH.  "" means only when it is asked for with --shell
+	Owned    bool   // the file only exists for devsecrets, so uninstall deletes it instead of leaving it empty
+}
+
+/*
+returns the startup files that setup can install into and uninstall removes the block from.  the files in
+$HOME/.config (or $XDG_CONFIG_HOME) are where fish, PowerShell and nushell look for them on Linux and macOS
+*/
+func StartupFiles(homeDir string) []StartupFile {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return []StartupFile{
+		{"bash", "bash", filepath.Join(homeDir, ".bashrc"), "bash", false},
+		{"zsh", "zsh", filepath.Join(homeDir, ".zshrc"), "zsh", false},
+		{"fish", "fish", filepath.Join(configHome, "fish", "conf.d", "devsecrets.fish"), "fish", true},
+		{"pwsh", "pwsh", filepath.Join(configHome, "powershell", "Microsoft.PowerShell_profile.ps1"), "pwsh", false},
+		{"nu", "nu", filepath.Join(configHome, "nushell", "env.nu"), "nu", false},
+		// login shells run /etc/profile.d/*.sh with sh, and the bash hook is POSIX
+		{"profile.d", "bash", ProfileD, "", true},
+	}
+}
+
+// returns the startup files of the shells that are installed
+func Installed(files []StartupFile) (installed []StartupFile) {
+	for _, f := range files {
+		if f.Program == "" {
+			continue
+		}
+		if _, err := exec.LookPath(f.Program); err == nil {
+			installed = append(installed, f)
+		}
+	}
+	return
+}
+
+// returns the startup files of the shells in names, in the order of files
+func Select(files []StartupFile, names []string) (selected []StartupFile, err error) {
+	valid := []string{}
+	for _, f := range files {
+		valid = append(valid, f.Shell)
+	}
+	for _, name := range names {
+		found := false
+		for _, f := range files {
+			found = found || f.Shell == name
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown shell \"%s\".  valid shells are: %s", name, strings.Join(valid, ", "))
+		}
+	}
+	for _, f := range files {
+		for _, name := range names {
+			if f.Shell == name {
+				selected = append(selected, f)
+				break
+			}
+		}
+	}
+	return
+}
+
+/*
+reads a startup file.  a startup file that is a link (e.g. into a dotfiles repo) is followed so that it stays a link
+when it is written, and fileName is where it points.  a file that doesn't exist is empty
+*/
+func ReadStartupFile(fileName string) (target string, contents string, err error) {
+	target = fileName
+	if resolved, err := filepath.EvalSymlinks(fileName); err == nil {
+		target = resolved
+	}
+	bytes, err := os.ReadFile(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return target, "", nil
+	}
+	return target, string(bytes), err
+}
+
+/*
+replaces the startup file with contents.  if the file exists, it is copied to a backup next to it with a timestamp in
+its name first.  backup is "" if there was nothing to back up.  the directory is created for shells that keep their
+startup files in one that may not exist yet (e.g. fish's conf.d)
+*/
+func WriteStartupFile(fileName string, contents string) (backup string, err error) {
+	perm := fs.FileMode(0644)
+	if info, err := os.Stat(fileName); err == nil {
+		old, err := os.ReadFile(fileName)
+		if err != nil {
+			return "", err
+		}
+		perm = info.Mode().Perm()
+		backup = fileName + ".devsecrets-" + time.Now().Format("20060102-150405") + ".bak"
+		if err = os.WriteFile(backup, old, perm); err != nil {
+			return "", err
+		}
+	} else if err = os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return "", err
+	}
+	return backup, wrappers.WriteFileAtomic(fileName, []byte(contents), perm)
+}
@@ -0,0 +1,92 @@
+/*
+remembers what the user decided about secrets that update asked for and didn't get a value for, so that a new
+terminal doesn't ask again.  each project keeps its decisions in $HOME/.config/devsecrets/projects/<name>/state.json:
+
+	{
+	    "secrets": {
+	        "GITLAB_PAT": { "decision": "skip", "until": "2026-10-17T10:00:00Z" },
+	        "SLACK_TOKEN": { "decision": "never" }
+	    }
+	}
+
+"skip" only lasts until its expiry, after which update asks again.  "never" lasts until the user sets the secret with
+"devsecrets update --name <name>".
+*/
+package state
+
+import (
+	"devsecrets/wrappers"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+type Decision string
+
+const (
+	SkipForNow Decision = "skip"  // don't ask again until the record expires
+	NeverAsk   Decision = "never" // don't ask again
+)
+
+// what the user decided about one secret
+type Record struct {
+	Decision Decision   `json:"decision"`
+	Until    *time.Time `json:"until,omitempty"` // nil means the record doesn't expire
+}
+
+type State struct {
+	FileName string            `json:"-"`
+	Secrets  map[string]Record `json:"secrets"`
+}
+
+// reads the state file.  a file that doesn't exist yet is an empty state
+func Load(fileName string) (*State, error) {
+	s := &State{FileName: fileName, Secrets: map[string]Record{}}
+	bytes, err := os.ReadFile(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(bytes, s); err != nil {
+		return nil, err
+	}
+	if s.Secrets == nil {
+		s.Secrets = map[string]Record{}
+	}
+	return s, nil
+}
+
+func (s *State) Save() error {
+	bytes, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return err
+	}
+	return wrappers.WriteFileAtomic(s.FileName, append(bytes, '\n'), 0600)
+}
+
+// records the decision for the secret.  a zero until means it never expires
+func (s *State) Set(name string, decision Decision, until time.Time) {
+	record := Record{Decision: decision}
+	if !until.IsZero() {
+		record.Until = &until
+	}
+	s.Secrets[name] = record
+}
+
+// forgets the decision for the secret, e.g. because it has a value now
+func (s *State) Clear(name string) {
+	delete(s.Secrets, name)
+}
+
+// returns the decision for the secret if there is one that hasn't expired at now
+func (s *State) Get(name string, now time.Time) (record Record, found bool) {
+	record, found = s.Secrets[name]
+	if found && record.Until != nil && !now.Before(*record.Until) {
+		return Record{}, false
+	}
+	return
+}
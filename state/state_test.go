@@ -0,0 +1,41 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateSaveLoad(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "state.json")
+	s, err := Load(fileName)
+	if err != nil || len(s.Secrets) != 0 {
+		t.Fatalf("Load() of a new file = %+v, %v", s, err)
+	}
+
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	s.Set("LATER", SkipForNow, now.Add(time.Hour))
+	s.Set("NEVER", NeverAsk, time.Time{})
+	s.Set("CLEARED", NeverAsk, time.Time{})
+	s.Clear("CLEARED")
+	if err = s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	s, err = Load(fileName)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if r, found := s.Get("LATER", now); !found || r.Decision != SkipForNow {
+		t.Errorf("Get(LATER) = %+v, %v", r, found)
+	}
+	if _, found := s.Get("LATER", now.Add(time.Hour)); found {
+		t.Error("Get(LATER) found a record that expired")
+	}
+	if r, found := s.Get("NEVER", now.AddDate(10, 0, 0)); !found || r.Decision != NeverAsk {
+		t.Errorf("Get(NEVER) = %+v, %v", r, found)
+	}
+	if _, found := s.Get("CLEARED", now); found {
+		t.Error("Get(CLEARED) found a record that was cleared")
+	}
+}
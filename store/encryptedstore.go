@@ -0,0 +1,289 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"devsecrets/config"
+	"devsecrets/envfile"
+	"devsecrets/wrappers"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func init() {
+	Register("encrypted", func(fileName string) (SecretStore, error) {
+		return &EncryptedStore{
+			FileName:   fileName + ".enc",
+			KeyFile:    filepath.Join(config.GetConfigDir(), "key"),
+			Passphrase: os.Getenv(PassphraseEnvVar),
+		}, nil
+	})
+}
+
+// when this is set, the encrypted store uses a key derived from it instead of the key file
+const PassphraseEnvVar = "DEVSECRETS_PASSPHRASE"
+
+/*
+the file starts with a header that says how the key was made, followed by the nonce and the AES-256-GCM encrypted
+text of an env file:
+
+	magic (8 bytes) | mode (1 byte) | salt (16 bytes) | nonce (12 bytes) | ciphertext
+
+the header is authenticated along with the ciphertext, so it can't be changed without decryption failing.
+*/
+var encryptedMagic = []byte("DSECENC1")
+
+const (
+	modeKeyFile    = byte('k') // the key is the 32 bytes in the key file
+	modePassphrase = byte('p') // the key is derived from the passphrase and the salt with PBKDF2
+	saltSize       = 16
+	kdfIterations  = 600000
+	keySize        = 32
+)
+
+/*
+a store that keeps the env file text encrypted on disk.  the plain text is never written to a file -- the
+shell gets the values with 'eval "$(devsecrets env)"'.  the key is either derived from $DEVSECRETS_PASSPHRASE or read
+from KeyFile, which is created with a random key the first time it is needed.
+*/
+type EncryptedStore struct {
+	FileName   string
+	KeyFile    string
+	Passphrase string
+
+	// the KDF is slow on purpose, so the last derived key is kept and its salt reused when the file is rewritten
+	salt []byte
+	key  []byte
+}
+
+func (e *EncryptedStore) Get(name string) (entry Entry, found bool, err error) {
+	entries, err := e.read()
+	if err != nil {
+		return
+	}
+	if i := envfile.Find(entries, name); i != -1 {
+		return entries[i], true, nil
+	}
+	return
+}
+
+func (e *EncryptedStore) Set(entry Entry) error {
+	entries, err := e.read()
+	if err != nil {
+		return err
+	}
+	if i := envfile.Find(entries, entry.Name); i != -1 {
+		entries[i] = entry
+	} else {
+		entries = append(entries, entry)
+	}
+	return e.write(entries)
+}
+
+func (e *EncryptedStore) Delete(name string) error {
+	entries, err := e.read()
+	if err != nil {
+		return err
+	}
+	if envfile.Find(entries, name) == -1 {
+		return nil
+	}
+	return e.write(envfile.Remove(entries, name))
+}
+
+func (e *EncryptedStore) List() ([]Entry, error) {
+	return e.read()
+}
+
+func (e *EncryptedStore) Location() string {
+	return e.FileName
+}
+
+// the key file is one of the files -- without the encrypted file there is nothing for it to protect
+func (e *EncryptedStore) Files() []string {
+	return []string{e.FileName, e.KeyFile}
+}
+
+func (e *EncryptedStore) read() (entries []Entry, err error) {
+	data, err := os.ReadFile(e.FileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	headerSize := len(encryptedMagic) + 1 + saltSize
+	if len(data) < headerSize || !bytes.Equal(data[:len(encryptedMagic)], encryptedMagic) {
+		return nil, fmt.Errorf("%s is not a devsecrets encrypted file", e.FileName)
+	}
+	header := data[:headerSize]
+	mode := header[len(encryptedMagic)]
+	salt := header[len(encryptedMagic)+1:]
+
+	gcm, err := e.cipher(mode, salt, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < headerSize+gcm.NonceSize() {
+		return nil, fmt.Errorf("%s is truncated", e.FileName)
+	}
+	nonce := data[headerSize : headerSize+gcm.NonceSize()]
+	plainText, err := gcm.Open(nil, nonce, data[headerSize+gcm.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt %s.  is the passphrase or key file correct? %w", e.FileName, err)
+	}
+	return envfile.Parse(string(plainText)), nil
+}
+
+func (e *EncryptedStore) write(entries []Entry) error {
+	plainText, err := envfile.Format(entries)
+	if err != nil {
+		return err
+	}
+	mode := modeKeyFile
+	if e.Passphrase != "" {
+		mode = modePassphrase
+	}
+	salt := e.salt
+	if salt == nil {
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+	}
+	gcm, err := e.cipher(mode, salt, true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	header := append(append(append([]byte{}, encryptedMagic...), mode), salt...)
+	data := append(append([]byte{}, header...), nonce...)
+	data = gcm.Seal(data, nonce, []byte(plainText), header)
+	return wrappers.WriteFileAtomic(e.FileName, data, 0600)
+}
+
+/*
+returns the AES-GCM cipher for the mode the file was (or will be) encrypted with.  the key file is only created when
+create is set -- a file that was encrypted with a key that is gone can't be read with a new one
+*/
+func (e *EncryptedStore) cipher(mode byte, salt []byte, create bool) (cipher.AEAD, error) {
+	var key []byte
+	var err error
+	switch mode {
+	case modePassphrase:
+		if e.Passphrase == "" {
+			return nil, fmt.Errorf("%s is encrypted with a passphrase.  set %s to unlock it", e.FileName, PassphraseEnvVar)
+		}
+		if e.key == nil || !bytes.Equal(salt, e.salt) {
+			e.salt = append([]byte{}, salt...)
+			e.key = pbkdf2([]byte(e.Passphrase), salt, kdfIterations, keySize)
+		}
+		key = e.key
+	case modeKeyFile:
+		if create {
+			key, err = e.readOrCreateKey()
+		} else {
+			key, err = e.readKey()
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s was encrypted with an unknown key mode", e.FileName)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (e *EncryptedStore) readKey() ([]byte, error) {
+	key, err := os.ReadFile(e.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("%s must contain a %d byte key", e.KeyFile, keySize)
+	}
+	return key, nil
+}
+
+/*
+the key file is created the first time it is needed.  it is only readable by the user -- anybody who can read it can
+read the secrets.  every project shares the key, so two updates can get here at the same time: the file is created
+with O_EXCL so only one of them writes a key, and the other one reads that key instead of replacing it
+*/
+func (e *EncryptedStore) readOrCreateKey() (key []byte, err error) {
+	key, err = e.readKey()
+	if !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	key = make([]byte, keySize)
+	if _, err = rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(e.KeyFile), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(e.KeyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return e.waitForKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Write(key); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return key, file.Close()
+}
+
+// reads the key that another process is writing.  the file exists as soon as it is created, before the key is in it
+func (e *EncryptedStore) waitForKey() (key []byte, err error) {
+	for i := 0; i < 50; i++ {
+		if key, err = e.readKey(); err == nil {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return
+}
+
+// PBKDF2 with HMAC-SHA256 (RFC 8018) -- small enough that it isn't worth a dependency
+func pbkdf2(password []byte, salt []byte, iterations int, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"devsecrets/envfile"
+)
+
+func init() {
+	Register(DefaultStore, func(fileName string) (SecretStore, error) {
+		return &FileStore{FileName: fileName}, nil
+	})
+}
+
+/*
+the default store: the env file of the project, which the shell loads with "devsecrets env".  every call reads the
+file so that the store never works from a stale copy.  lines that somebody added to the file by hand are not secrets
+in the store, but they are kept in the unmanaged section of the file.
+*/
+type FileStore struct {
+	FileName string
+}
+
+func (f *FileStore) Get(name string) (entry Entry, found bool, err error) {
+	file, err := envfile.ReadFile(f.FileName)
+	if err != nil {
+		return
+	}
+	if i := envfile.Find(file.Entries, name); i != -1 {
+		return file.Entries[i], true, nil
+	}
+	return
+}
+
+func (f *FileStore) Set(entry Entry) error {
+	file, err := envfile.ReadFile(f.FileName)
+	if err != nil {
+		return err
+	}
+	if i := envfile.Find(file.Entries, entry.Name); i != -1 {
+		file.Entries[i] = entry
+	} else {
+		file.Entries = append(file.Entries, entry)
+	}
+	file.Adopt(entry.Name)
+	return envfile.WriteFile(f.FileName, file)
+}
+
+func (f *FileStore) Delete(name string) error {
+	file, err := envfile.ReadFile(f.FileName)
+	if err != nil {
+		return err
+	}
+	if envfile.Find(file.Entries, name) == -1 {
+		return nil
+	}
+	file.Entries = envfile.Remove(file.Entries, name)
+	return envfile.WriteFile(f.FileName, file)
+}
+
+func (f *FileStore) List() ([]Entry, error) {
+	file, err := envfile.ReadFile(f.FileName)
+	return file.Entries, err
+}
+
+// returns the lines in the file that aren't managed by devsecrets.  they are bash, so only bash and zsh can load them
+func (f *FileStore) Unmanaged() (string, error) {
+	file, err := envfile.ReadFile(f.FileName)
+	return file.Unmanaged, err
+}
+
+// adds lines to the end of the unmanaged section of the file
+func (f *FileStore) AddUnmanaged(lines string) error {
+	file, err := envfile.ReadFile(f.FileName)
+	if err != nil {
+		return err
+	}
+	if file.Unmanaged != "" {
+		lines = file.Unmanaged + "\n\n" + lines
+	}
+	file.Unmanaged = lines
+	return envfile.WriteFile(f.FileName, file)
+}
+
+func (f *FileStore) Location() string {
+	return f.FileName
+}
+
+func (f *FileStore) Files() []string {
+	return []string{f.FileName}
+}
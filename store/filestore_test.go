@@ -0,0 +1,52 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	f := &FileStore{FileName: filepath.Join(t.TempDir(), ".devsecrets.env")}
+
+	entries, err := f.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("List() on a missing file = %v, %v", entries, err)
+	}
+
+	f.Set(Entry{Name: "FIRST", Value: "one", Description: "the first secret"})
+	f.Set(Entry{Name: "SECOND", Value: "two"})
+	f.Set(Entry{Name: "FIRST", Value: "uno", Description: "the first secret"})
+
+	entry, found, err := f.Get("FIRST")
+	if err != nil || !found || entry.Value != "uno" || entry.Description != "the first secret" {
+		t.Errorf("Get(FIRST) = %v, %v, %v", entry, found, err)
+	}
+
+	if err = f.Delete("SECOND"); err != nil {
+		t.Errorf("Delete(SECOND) error = %v", err)
+	}
+	if err = f.Delete("NOT_THERE"); err != nil {
+		t.Errorf("Delete(NOT_THERE) error = %v", err)
+	}
+	if _, found, _ = f.Get("SECOND"); found {
+		t.Error("SECOND was not deleted")
+	}
+
+	entries, _ = f.List()
+	if len(entries) != 1 || entries[0].Name != "FIRST" {
+		t.Errorf("List() = %v", entries)
+	}
+}
+
+func TestNew(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "secrets.env")
+	if s, err := New("", fileName); err != nil || s.Location() != fileName {
+		t.Errorf("New(\"\") = %v, %v", s, err)
+	}
+	if s, err := New("encrypted", fileName); err != nil || s.Location() != fileName+".enc" {
+		t.Errorf("New(\"encrypted\") = %v, %v", s, err)
+	}
+	if _, err := New("no-such-store", fileName); err == nil {
+		t.Error("New() accepted an unknown store")
+	}
+}
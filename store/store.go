@@ -0,0 +1,71 @@
+/*
+a SecretStore is where the values of the secrets live between runs of devsecrets.  update writes to it, delete removes
+from it and verify reads from it.  the store is picked with "options.store" in devsecrets.json -- the default is the
+plain env file of the project (see config.GetSecretFileName()).
+
+to add a store, implement SecretStore and call Register() from an init() function in this package.
+*/
+package store
+
+import (
+	"devsecrets/config"
+	"devsecrets/envfile"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Entry is one secret in a store: the value plus the metadata that verify reports on
+type Entry = envfile.Entry
+
+type SecretStore interface {
+	Get(name string) (entry Entry, found bool, err error) // found is false if the store doesn't have the secret
+	Set(entry Entry) error                                // adds the secret or replaces its value
+	Delete(name string) error                             // deleting a secret that isn't there is not an error
+	List() ([]Entry, error)                               // every secret in the store
+	Location() string                                     // where the secrets are, for messages to the user
+}
+
+// a store that keeps the secrets in files on this machine.  uninstall --purge removes the files
+type FileBacked interface {
+	Files() []string // every file the store reads or writes, whether it exists or not
+}
+
+const DefaultStore = "file"
+
+/*
+the factory of a store is passed the env file of the project.  a store that doesn't keep the secrets in it keeps them
+next to it, so that each project still gets its own
+*/
+var factories = map[string]func(fileName string) (SecretStore, error){}
+
+// makes a store available to "options.store"
+func Register(name string, factory func(fileName string) (SecretStore, error)) {
+	factories[name] = factory
+}
+
+// returns the store with the name for the env file of a project.  "" is the default store
+func New(name string, fileName string) (SecretStore, error) {
+	if name == "" {
+		name = DefaultStore
+	}
+	factory, found := factories[name]
+	if !found {
+		return nil, fmt.Errorf("unknown store \"%s\".  valid stores are: %s", name, strings.Join(Names(), ", "))
+	}
+	return factory(fileName)
+}
+
+// returns the store selected in the manifest that was loaded with config.LoadSecretFile()
+func FromConfig() (SecretStore, error) {
+	return New(config.LocalSecrets.Options.Store, config.GetSecretFileName())
+}
+
+// returns the names of the registered stores in alphabetical order
+func Names() (names []string) {
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
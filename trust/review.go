@@ -0,0 +1,51 @@
+package trust
+
+import (
+	"devsecrets/globals"
+	"devsecrets/wrappers"
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+/*
+checks each file against the store.  if ask is set, the user is shown what changed in the files they haven't approved
+(the whole file if they never approved it) and asked if they trust them -- the answer covers all of them, and a yes is
+saved.  returns the files that are still not trusted
+*/
+func Review(s *Store, fileNames []string, ask bool) (untrusted []string, err error) {
+	changes := []Change{}
+	for _, fileName := range fileNames {
+		change, err := s.Check(fileName)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // there is nothing to run -- running it reports the missing file
+		}
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+			untrusted = append(untrusted, change.FileName)
+		}
+	}
+	if len(changes) == 0 || !ask {
+		return
+	}
+
+	for _, change := range changes {
+		if change.Approved == "" {
+			globals.EchoWarning(change.FileName, " has not been approved yet:\n")
+		} else {
+			globals.EchoWarning(change.FileName, " changed since it was approved:\n")
+		}
+		globals.EchoInfo(wrappers.Diff(change.Approved, change.Current), "\n")
+	}
+	answer := globals.EnterString("devsecrets runs these files with your credentials.  trust them? [y/N]: ")
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		return
+	}
+	for _, change := range changes {
+		s.Approve(change)
+	}
+	return nil, s.Save()
+}
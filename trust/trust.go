@@ -0,0 +1,131 @@
+/*
+remembers which versions of the manifest and of the shell scripts it runs the user has approved, so that update never
+runs a script that changed in a pulled branch without the user seeing the change first -- like "direnv allow".  the
+approvals are kept in $HOME/.config/devsecrets/trust.json:
+
+	{
+	    "files": {
+	        "/workspaces/app/devsecrets.json": { "sha256": "9f86d0...", "content": "...", "approved": "2026-10-16T10:00:00Z" }
+	    }
+	}
+
+the approved content is kept so that the change can be shown as a diff (see wrappers.Diff()) when the file changes.
+*/
+package trust
+
+import (
+	"crypto/sha256"
+	"devsecrets/wrappers"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// the version of a file that the user approved
+type Record struct {
+	SHA256   string    `json:"sha256"`
+	Content  string    `json:"content"`
+	Approved time.Time `json:"approved"`
+}
+
+type Store struct {
+	FileName     string            `json:"-"`
+	LockFileName string            `json:"-"`     // locked while the file is written.  see Save()
+	Files        map[string]Record `json:"files"` // by the absolute path of the file
+
+	approved map[string]Record // what Approve() added since the file was loaded
+}
+
+// a file whose current content the user hasn't approved
+type Change struct {
+	FileName string // the absolute path
+	Approved string // the content the user approved last time.  "" if the file was never approved
+	Current  string
+}
+
+// reads the trust file.  a file that doesn't exist yet trusts nothing
+func Load(fileName string, lockFileName string) (*Store, error) {
+	s := &Store{FileName: fileName, LockFileName: lockFileName, Files: map[string]Record{}, approved: map[string]Record{}}
+	bytes, err := os.ReadFile(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(bytes, s); err != nil {
+		return nil, err
+	}
+	if s.Files == nil {
+		s.Files = map[string]Record{}
+	}
+	return s, nil
+}
+
+/*
+writes the approvals to the file.  other projects approve their files in the same file, so it is read again while the
+lock is held and only what was approved since it was loaded is added -- the user can take a long time to answer, and
+what another terminal approved in the meantime mustn't be lost
+*/
+func (s *Store) Save() error {
+	unlock, err := wrappers.LockFile(s.LockFileName)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	current, err := Load(s.FileName, s.LockFileName)
+	if err != nil {
+		return err
+	}
+	for fileName, record := range s.approved {
+		current.Files[fileName] = record
+	}
+	s.Files = current.Files
+	bytes, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return err
+	}
+	return wrappers.WriteFileAtomic(s.FileName, append(bytes, '\n'), 0600)
+}
+
+/*
+returns the change if the file isn't the version that the user approved, or nil if it is.  a relative fileName is
+relative to the current directory, which is how update runs scripts
+*/
+func (s *Store) Check(fileName string) (*Change, error) {
+	fileName, err := filepath.Abs(fileName)
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	record, found := s.Files[fileName]
+	if found && record.SHA256 == hash(bytes) {
+		return nil, nil
+	}
+	return &Change{FileName: fileName, Approved: record.Content, Current: string(bytes)}, nil
+}
+
+// returns true if the file is the version the user approved.  a file that can't be read is never trusted
+func (s *Store) IsTrusted(fileName string) bool {
+	change, err := s.Check(fileName)
+	return err == nil && change == nil
+}
+
+// records that the user approved the current content of the file
+func (s *Store) Approve(change Change) {
+	record := Record{SHA256: hash([]byte(change.Current)), Content: change.Current, Approved: time.Now().UTC()}
+	s.Files[change.FileName] = record
+	s.approved[change.FileName] = record
+}
+
+func hash(bytes []byte) string {
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:])
+}
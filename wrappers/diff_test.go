@@ -0,0 +1,15 @@
+package wrappers
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	new := "1\n2\n3\n4\n5\nsix\n7\n8\n9\n10\n"
+	want := "...\n  3\n  4\n  5\n- 6\n+ six\n  7\n  8\n  9\n+ 10\n"
+	if got := Diff(old, new); got != want {
+		t.Errorf("Diff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Diff("", "echo hi\n"); got != "+ echo hi\n" {
+		t.Errorf("Diff() of a new file = %q", got)
+	}
+}
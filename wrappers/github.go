@@ -0,0 +1,95 @@
+/*
+wrappers over the GitHub cli (gh) for storing secrets as GitHub Codespaces user secrets.  GitHub never gives the value
+of a secret back -- the only way to read a user secret is from the environment of a Codespace that it is scoped to.
+*/
+package wrappers
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// GhExec runs the gh cli with the string on stdin.  it is a variable so that tests can replace it
+var GhExec = CmdExecOsWithStdin
+
+/*
+returns the owner/name of the current repository.  in a Codespace this is in $GITHUB_REPOSITORY, otherwise we ask gh
+about the repo in the current directory
+*/
+func GitHubRepository() (repo string, err error) {
+	repo = os.Getenv("GITHUB_REPOSITORY")
+	if repo != "" {
+		return
+	}
+	stdout, _, err := GhExec("gh", []string{"repo", "view", "--json", "nameWithOwner", "--jq", ".nameWithOwner"}, "")
+	if err != nil {
+		return "", err
+	}
+	repo = strings.TrimSpace(stdout.String())
+	if repo == "" {
+		err = errors.New("gh did not return the name of the current repository")
+	}
+	return
+}
+
+/*
+creates or updates a user secret and adds the repo to the repositories that can use it.  "--repos" would replace the
+list of repositories, and the other repos that share the secret would lose it.  gh reads the value from stdin when
+there is no --body, so the value never shows up in the command line of a process
+*/
+func SetGitHubUserSecret(name string, value string, repo string) (err error) {
+	id, err := gitHubRepositoryID(repo)
+	if err != nil {
+		return
+	}
+	if _, _, err = GhExec("gh", []string{"secret", "set", name, "--user"}, value); err != nil {
+		return
+	}
+	_, _, err = GhExec("gh", []string{"api", "--method", "PUT", "user/codespaces/secrets/" + name + "/repositories/" + id}, "")
+	return
+}
+
+/*
+takes the repo off of the repositories that can use the user secret.  the secret itself is left alone: it is one
+secret for every repo the user picked, and the other repos may still need it
+*/
+func DeleteGitHubUserSecret(name string, repo string) (err error) {
+	id, err := gitHubRepositoryID(repo)
+	if err != nil {
+		return
+	}
+	_, _, err = GhExec("gh", []string{"api", "--method", "DELETE",
+		"user/codespaces/secrets/" + name + "/repositories/" + id}, "")
+	return
+}
+
+// the API that picks the repositories of a user secret takes the numeric id of the repo, not its owner/name
+func gitHubRepositoryID(repo string) (id string, err error) {
+	stdout, _, err := GhExec("gh", []string{"api", "repos/" + repo, "--jq", ".id"}, "")
+	if err != nil {
+		return
+	}
+	id = strings.TrimSpace(stdout.String())
+	if id == "" {
+		err = errors.New("gh did not return the id of " + repo)
+	}
+	return
+}
+
+// returns true when running in a GitHub Codespace
+func InCodespace() bool {
+	return os.Getenv("CODESPACES") == "true"
+}
+
+/*
+user secrets are put into the environment when a Codespace starts, so this is how we read them back.  outside of a
+Codespace there is nothing to read.
+*/
+func GetCodespaceSecret(name string) (value string, found bool) {
+	if !InCodespace() {
+		return "", false
+	}
+	value, found = os.LookupEnv(name)
+	return
+}
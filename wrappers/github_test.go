@@ -0,0 +1,76 @@
+package wrappers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*
+puts a fake gh on the front of the PATH.  it writes its arguments to a log file in the temp directory and answers
+"repo view" and "api repos/..." with a made up repository.  what "secret set" reads from stdin is logged too
+*/
+func fakeGh(t *testing.T) (logFile string) {
+	dir := t.TempDir()
+	logFile = filepath.Join(dir, "gh.log")
+	script := "#!/bin/bash\n" +
+		"echo \"$@\" >> " + logFile + "\n" +
+		"if [[ $1 == repo ]]; then echo test-owner/test-repo; fi\n" +
+		"if [[ $1 == api && $2 == repos/* ]]; then echo 1234; fi\n" +
+		"if [[ $1 == secret ]]; then echo \"stdin: $(cat)\" >> " + logFile + "; fi\n"
+	err := os.WriteFile(filepath.Join(dir, "gh"), []byte(script), 0755)
+	if err != nil {
+		t.Fatal("Error writing fake gh " + err.Error())
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return
+}
+
+func TestGitHubUserSecrets(t *testing.T) {
+	logFile := fakeGh(t)
+	t.Setenv("GITHUB_REPOSITORY", "")
+
+	repo, err := GitHubRepository()
+	if err != nil || repo != "test-owner/test-repo" {
+		t.Errorf("GitHubRepository() = %v, %v", repo, err)
+	}
+	if err = SetGitHubUserSecret("GITLAB_PAT", "the value", repo); err != nil {
+		t.Errorf("SetGitHubUserSecret() error = %v", err)
+	}
+	if err = DeleteGitHubUserSecret("GITLAB_PAT", repo); err != nil {
+		t.Errorf("DeleteGitHubUserSecret() error = %v", err)
+	}
+
+	out, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal("Error reading gh log " + err.Error())
+	}
+	expected := []string{
+		"repo view --json nameWithOwner --jq .nameWithOwner",
+		"api repos/test-owner/test-repo --jq .id",
+		"secret set GITLAB_PAT --user",
+		"stdin: the value",
+		"api --method PUT user/codespaces/secrets/GITLAB_PAT/repositories/1234",
+		"api repos/test-owner/test-repo --jq .id",
+		"api --method DELETE user/codespaces/secrets/GITLAB_PAT/repositories/1234",
+	}
+	got := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected gh calls.  Expected:\n%s\nGot:\n%s", strings.Join(expected, "\n"), string(out))
+	}
+}
+
+func TestGetCodespaceSecret(t *testing.T) {
+	t.Setenv("DEVSECRETS_TEST_SECRET", "value")
+
+	t.Setenv("CODESPACES", "")
+	if _, found := GetCodespaceSecret("DEVSECRETS_TEST_SECRET"); found {
+		t.Error("secret found outside of a Codespace")
+	}
+
+	t.Setenv("CODESPACES", "true")
+	if val, found := GetCodespaceSecret("DEVSECRETS_TEST_SECRET"); !found || val != "value" {
+		t.Errorf("GetCodespaceSecret() = %v, %v", val, found)
+	}
+}
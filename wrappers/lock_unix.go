@@ -0,0 +1,33 @@
+//go:build unix
+
+package wrappers
+
+import (
+	"devsecrets/globals"
+	"os"
+	"syscall"
+)
+
+/*
+takes an exclusive lock on lockFile (creating it if needed) and returns the function that releases it.  if another
+process has the lock, this waits for it -- e.g. when several terminals open at once and each runs "devsecrets update"
+*/
+func LockFile(lockFile string) (unlock func(), err error) {
+	file, err := os.OpenFile(lockFile, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	fd := int(file.Fd())
+	if syscall.Flock(fd, syscall.LOCK_EX|syscall.LOCK_NB) != nil {
+		globals.EchoWarning("Waiting for another devsecrets to finish with ", lockFile, "\n")
+		if err = syscall.Flock(fd, syscall.LOCK_EX); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	unlock = func() {
+		syscall.Flock(fd, syscall.LOCK_UN)
+		file.Close()
+	}
+	return
+}
@@ -14,7 +14,9 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
 /*
@@ -27,6 +29,12 @@ if the program echos to stdout, then the value is in stdout
 this does not echoError on an error because the caller might expect an error (eg a negative test)
 */
 func CmdExecOs(name string, args []string) (stdout bytes.Buffer, stderr bytes.Buffer, err error) {
+	return CmdExecOsWithStdin(name, args, "")
+}
+
+// like CmdExecOs(), but the command reads stdin from the string.  this keeps a secret off of the command line, where
+// every process can read it from /proc
+func CmdExecOsWithStdin(name string, args []string, stdin string) (stdout bytes.Buffer, stderr bytes.Buffer, err error) {
 	if globals.Verbose {
 		// globals.Echo(CmdArgsToString(name, args) + "\n")
 		s := globals.HidePat(CmdArgsToString(name, args))
@@ -35,6 +43,7 @@ func CmdExecOs(name string, args []string) (stdout bytes.Buffer, stderr bytes.Bu
 
 	cmd := exec.Command(name, args...)
 
+	cmd.Stdin = strings.NewReader(stdin)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	err = cmd.Run()
@@ -194,44 +203,180 @@ func ReplaceFile(fileName string, with string) error {
 	return nil
 }
 /*
-this executes a bash script and then returns the *last line* of the output -- 
-so whatever the script wants to return should be the last echo call.
+replaces fileName with data without ever leaving a half written file behind: the data is written to a temp file in
+the same directory, flushed to disk and then renamed over fileName.  if anything fails, fileName is unchanged.
 */
-func ExecBash(script string) (string, error) {
-	// Define the command to execute the script
-	var buf bytes.Buffer
-	// Create a multi-writer to write to both os.Stdout and the buffer
-	mw := io.MultiWriter(os.Stdout, &buf)
+func WriteFileAtomic(fileName string, data []byte, perm os.FileMode) (err error) {
+	temp, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			temp.Close()
+			os.Remove(temp.Name())
+		}
+	}()
 
-	cmd := &exec.Cmd {
-		Path: script,
-		Stdout: mw,
-		Stdin: os.Stdin, 
-		Stderr: os.Stderr,
+	if err = temp.Chmod(perm); err != nil {
+		return
+	}
+	if _, err = temp.Write(data); err != nil {
+		return
+	}
+	if err = temp.Sync(); err != nil {
+		return
+	}
+	if err = temp.Close(); err != nil {
+		return
+	}
+	return os.Rename(temp.Name(), fileName)
+}
+
+// a shell script for ExecBash to run
+type Script struct {
+	Path    string
+	Args    []string
+	Dir     string        // where the script runs.  "" is the current directory
+	Timeout time.Duration // the script is killed if it runs longer than this.  0 waits forever
+	Env     []string      // NAME=value pairs added to the environment of the script
+
+	// the script can read from the terminal and what it prints is shown to the user.  a script that isn't interactive
+	// gets nothing on stdin, so several of them can run at the same time
+	Interactive bool
+}
 
+/*
+this executes a bash script and then returns what it wrote to the file in $DEVSECRETS_OUTPUT (see OutputEnvVar) or,
+if it didn't write anything there, the *last line* of the output that isn't blank -- so whatever the script wants to
+return should be the last echo call.  everything else the script prints is only for the user.
+
+what the script writes to stderr goes straight to the terminal so that the user sees why it failed.  a script that
+exits with anything but 0 returns an error and no value.
+*/
+func ExecBash(script Script) (string, error) {
+	// a relative Path is relative to Dir, but the script in devsecrets.json is relative to where update runs
+	path, err := filepath.Abs(script.Path)
+	if err != nil {
+		return "", err
+	}
+	// the script gets the write end of a pipe instead of a Writer: exec would wait for anything the script started
+	// that still has stdout open, even after the script was killed
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	outputFile, err := os.CreateTemp("", "devsecrets-output-*")
+	if err != nil {
+		return "", err
+	}
+	outputFile.Close()
+	defer os.Remove(outputFile.Name())
+
+	cmd := exec.Command(path, script.Args...)
+	cmd.Dir = script.Dir
+	cmd.Env = append(append(os.Environ(), script.Env...), OutputEnvVar+"="+outputFile.Name())
+	cmd.Stdout = writer
+	cmd.Stderr = os.Stderr
+	if script.Interactive {
+		cmd.Stdin = os.Stdin
+	}
+	if !script.Interactive || !globals.StdinIsTerminal() {
+		// nothing can be typed into the script, so it can run in its own process group and be killed with its children
+		setProcessGroup(cmd)
+	}
+	err = cmd.Start()
+	writer.Close()
+	if err != nil {
+		return "", fmt.Errorf("error running script: %w", err)
 	}
 
-	err := cmd.Run()
+	var buf bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		var output io.Writer = &buf
+		if script.Interactive {
+			// the output goes to the console as well so that a script can show the user what it is doing
+			output = io.MultiWriter(globals.Console(), &buf)
+		}
+		io.Copy(output, reader)
+		close(copied)
+	}()
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	var expired <-chan time.Time
+	if script.Timeout > 0 {
+		timer := time.NewTimer(script.Timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case err = <-exited:
+	case <-expired:
+		killProcessGroup(cmd)
+		<-exited
+		return "", fmt.Errorf("%s did not finish within %s", path, script.Timeout)
+	}
 	if err != nil {
 		return "", fmt.Errorf("error running script: %w", err)
 	}
+	select {
+	case <-copied:
+	case <-expired:
+		return "", fmt.Errorf("%s exited, but something it started is still writing to its output", path)
+	}
+
+	output, err := os.ReadFile(outputFile.Name())
+	if err != nil {
+		return "", err
+	}
+	if len(output) != 0 {
+		// "echo value > $DEVSECRETS_OUTPUT" adds a newline that isn't part of the value
+		return strings.TrimSuffix(string(output), "\n"), nil
+	}
 
 	// Create a scanner to read through the buffer
 	scanner := bufio.NewScanner(&buf)
 
-	// Keep scanning until the last line is reached
+	// Keep scanning until the last line is reached.  a blank line after the value is not the value
 	var lastLine string
 	for scanner.Scan() {
-		lastLine = scanner.Text()
+		if strings.TrimSpace(scanner.Text()) != "" {
+			lastLine = scanner.Text()
+		}
 	}
+	return lastLine, scanner.Err()
+}
 
-	// Check for scanner errors
-	err = scanner.Err()
-	if err != nil {
-		// Handle errors
-		fmt.Println("Error reading output:", err)
-		return "", err
+// the environment variable with the name of the file that a shell script can write its result to
+const OutputEnvVar = "DEVSECRETS_OUTPUT"
+
+// one value in the JSON result of a shell script
+type ScriptValue struct {
+	Value   string    `json:"value"`
+	Expires time.Time `json:"expires"` // when the value stops working, e.g. a token.  zero if it doesn't
+}
+
+// a value can be just the string, or an object with the value and its metadata
+func (v *ScriptValue) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &v.Value); err == nil {
+		return nil
 	}
+	type plain ScriptValue // without UnmarshalJSON
+	return json.Unmarshal(data, (*plain)(v))
+}
+
+/*
+parses the result of a shell script whose secret has "output": "json".  the result is an object with a value for each
+environment variable that the script sets, e.g.
 
-	return lastLine, nil
+	{"AZURE_SUB_ID": "...", "AZURE_TENANT_ID": {"value": "...", "expires": "2026-10-17T10:00:00Z"}}
+*/
+func ParseScriptJSON(output string) (values map[string]ScriptValue, err error) {
+	if err = json.Unmarshal([]byte(output), &values); err != nil {
+		return nil, fmt.Errorf("the script did not return a JSON object: %w", err)
+	}
+	return
 }
@@ -7,7 +7,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -128,3 +130,87 @@ func TestReplaceTextInFiles(t *testing.T) {
 		t.Error("Error in TestReplaceTextInFiles.  Expected", testRepoName, " got ", roundTrip.Repo)
 	}
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	fileName := dir + "/.devsecrets.env"
+	os.WriteFile(fileName, []byte("old"), 0644)
+
+	if err := WriteFileAtomic(fileName, []byte("new"), 0600); err != nil {
+		t.Fatal("Error in WriteFileAtomic " + err.Error())
+	}
+	data, _ := os.ReadFile(fileName)
+	if string(data) != "new" {
+		t.Error("Expected new, got ", string(data))
+	}
+	info, _ := os.Stat(fileName)
+	if info.Mode().Perm() != 0600 {
+		t.Error("Expected mode 0600, got ", info.Mode().Perm())
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Error("WriteFileAtomic left a temp file behind")
+	}
+}
+
+// writes a script to a temp directory and returns its path
+func writeScript(t *testing.T, body string) string {
+	script := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/bash\n"+body), 0755); err != nil {
+		t.Fatal("Error writing script " + err.Error())
+	}
+	return script
+}
+
+func TestExecBash(t *testing.T) {
+	dir := t.TempDir()
+	script := writeScript(t, "echo working\necho \"$1 $2 $(basename $PWD) $AZURE_SUB_ID\"\n")
+	val, err := ExecBash(Script{Path: script, Args: []string{"first", "second arg"}, Dir: dir, Env: []string{"AZURE_SUB_ID=sub"}})
+	if err != nil || val != "first second arg "+filepath.Base(dir)+" sub" {
+		t.Errorf("ExecBash() = %q, %v", val, err)
+	}
+
+	script = writeScript(t, "echo partial\necho failed >&2\nexit 3\n")
+	if val, err = ExecBash(Script{Path: script}); err == nil || val != "" {
+		t.Errorf("ExecBash() of a failing script = %q, %v", val, err)
+	}
+
+	script = writeScript(t, "sleep 10\necho too late\n")
+	start := time.Now()
+	if val, err = ExecBash(Script{Path: script, Timeout: 100 * time.Millisecond}); err == nil || val != "" {
+		t.Errorf("ExecBash() of a slow script = %q, %v", val, err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("ExecBash() didn't stop at the timeout")
+	}
+}
+
+func TestExecBashOutput(t *testing.T) {
+	script := writeScript(t, "echo value\necho\n")
+	if val, err := ExecBash(Script{Path: script}); err != nil || val != "value" {
+		t.Errorf("ExecBash() with a trailing blank line = %q, %v", val, err)
+	}
+
+	script = writeScript(t, "echo 'not the value'\nprintf 'line one\\nline two\\n' > \"$DEVSECRETS_OUTPUT\"\n")
+	if val, err := ExecBash(Script{Path: script}); err != nil || val != "line one\nline two" {
+		t.Errorf("ExecBash() with $DEVSECRETS_OUTPUT = %q, %v", val, err)
+	}
+}
+
+func TestParseScriptJSON(t *testing.T) {
+	values, err := ParseScriptJSON(`{"AZURE_SUB_ID": "sub", "AZURE_TOKEN": {"value": "token", "expires": "2026-10-17T10:00:00Z"}}`)
+	if err != nil {
+		t.Fatalf("ParseScriptJSON() error = %v", err)
+	}
+	if values["AZURE_SUB_ID"].Value != "sub" || !values["AZURE_SUB_ID"].Expires.IsZero() {
+		t.Errorf("AZURE_SUB_ID = %+v", values["AZURE_SUB_ID"])
+	}
+	expires := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	if values["AZURE_TOKEN"].Value != "token" || !values["AZURE_TOKEN"].Expires.Equal(expires) {
+		t.Errorf("AZURE_TOKEN = %+v", values["AZURE_TOKEN"])
+	}
+
+	if _, err = ParseScriptJSON("just a value"); err == nil {
+		t.Error("ParseScriptJSON() of a plain value didn't return an error")
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package wrappers
+
+import "os/exec"
+
+// process groups are only used on unix -- everywhere else only the command itself is killed
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
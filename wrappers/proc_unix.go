@@ -0,0 +1,22 @@
+//go:build unix
+
+package wrappers
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// runs the command in its own process group so that killProcessGroup() also kills whatever it started
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// kills the command, and everything it started if setProcessGroup() was called for it
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		return
+	}
+	cmd.Process.Kill()
+}